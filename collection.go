@@ -0,0 +1,300 @@
+package gocfg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KeyLister is an optional interface for value providers that can enumerate the keys they hold.
+// It is required to fill maps from a key prefix.
+type KeyLister interface {
+	Keys() []string
+}
+
+// getFieldParser retrieves the parser for a type. Registered parser providers are tried first, then the unmarshaler
+// interfaces implemented by the type, then the default parser providers, so that both override the default parsing.
+// Slices, arrays and maps are composed from the parsers of their element types before the default parser providers
+// are tried, so that separators and custom element parsers apply to them. Byte slices are left to the default
+// parser providers, which take the raw value.
+func (c *ConfigManager) getFieldParser(t reflect.Type, spec fieldSpec) (func(v string) (interface{}, error), bool) {
+	field := reflect.New(t).Elem()
+
+	if parser, ok := c.getParser(field, false); ok {
+		return parser, true
+	}
+
+	if parser, ok := getUnmarshalerParser(t); ok {
+		return parser, true
+	}
+
+	switch {
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8, t.Kind() == reflect.Array:
+		if parser, ok := c.getSliceParser(t, spec); ok {
+			return parser, true
+		}
+	case t.Kind() == reflect.Map:
+		if parser, ok := c.getMapParser(t, spec); ok {
+			return parser, true
+		}
+	}
+
+	return c.getParser(field, true)
+}
+
+// getSliceParser builds a parser for values like "value1,value2", using the separator configured for the field.
+// Elements of non-string types are trimmed of surrounding whitespace. Arrays require exactly as many elements as their length.
+func (c *ConfigManager) getSliceParser(t reflect.Type, spec fieldSpec) (func(v string) (interface{}, error), bool) {
+	elemParser, ok := c.getFieldParser(t.Elem(), spec)
+	if !ok {
+		return nil, false
+	}
+
+	return func(v string) (interface{}, error) {
+		parts := strings.Split(v, spec.separator)
+
+		var result reflect.Value
+		if t.Kind() == reflect.Array {
+			if len(parts) != t.Len() {
+				return nil, fmt.Errorf("expected %d elements, got %d", t.Len(), len(parts))
+			}
+			result = reflect.New(t).Elem()
+		} else {
+			result = reflect.MakeSlice(t, len(parts), len(parts))
+		}
+
+		for i, part := range parts {
+			if t.Elem().Kind() != reflect.String {
+				part = strings.TrimSpace(part)
+			}
+
+			elem, err := elemParser(part)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+
+			result.Index(i).Set(reflect.ValueOf(elem).Convert(t.Elem()))
+		}
+		return result.Interface(), nil
+	}, true
+}
+
+// getMapParser builds a parser for values like "key1:value1,key2:value2",
+// using the separators configured for the field
+func (c *ConfigManager) getMapParser(t reflect.Type, spec fieldSpec) (func(v string) (interface{}, error), bool) {
+	keyParser, ok := c.getFieldParser(t.Key(), spec)
+	if !ok {
+		return nil, false
+	}
+
+	elemParser, ok := c.getFieldParser(t.Elem(), spec)
+	if !ok {
+		return nil, false
+	}
+
+	return func(v string) (interface{}, error) {
+		result := reflect.MakeMap(t)
+		for i, pair := range strings.Split(v, spec.separator) {
+			kv := strings.SplitN(pair, spec.kvSeparator, 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("entry %d: missing key/value separator %q", i, spec.kvSeparator)
+			}
+
+			if err := setMapEntry(result, keyParser, elemParser, strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])); err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i, err)
+			}
+		}
+		return result.Interface(), nil
+	}, true
+}
+
+// setMapEntry parses a raw key and value and stores them in m
+func setMapEntry(m reflect.Value, keyParser, elemParser func(v string) (interface{}, error), rawKey, rawValue string) error {
+	k, err := keyParser(rawKey)
+	if err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
+
+	v, err := elemParser(rawValue)
+	if err != nil {
+		return fmt.Errorf("invalid value for key %q: %w", rawKey, err)
+	}
+
+	m.SetMapIndex(reflect.ValueOf(k).Convert(m.Type().Key()), reflect.ValueOf(v).Convert(m.Type().Elem()))
+
+	return nil
+}
+
+// unmarshalPrefixMap fills a map field with every key starting with the field key, e.g. FEATURE_FLAGS_ collects
+// FEATURE_FLAGS_SEARCH=true into map[SEARCH:true]. When no such keys exist, the default value is parsed as a single map value.
+func (c *ConfigManager) unmarshalPrefixMap(field reflect.Value, spec fieldSpec, state *unmarshalState) error {
+	var entries map[string]providedValue
+	if !c.forceDefaults {
+		var err error
+		if entries, err = c.getPrefixedValues(state.ctx, spec); err != nil {
+			return err
+		}
+	}
+
+	if len(entries) == 0 {
+		return c.unmarshalField(field, spec, state)
+	}
+
+	state.found = true
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if spec.unset {
+		defer c.unsetKeys(spec, keys...)
+	}
+
+	result := reflect.MakeMap(field.Type())
+	for _, k := range keys {
+		entry := entries[k]
+		if spec.expand {
+			expanded, err := state.expander.expand(entry.value, spec.path, k)
+			if providerErr, ok := err.(*ProviderError); ok {
+				return providerErr
+			}
+			if err != nil {
+				return &ExpansionError{spec.fieldError(entry.value, entry.provider, fmt.Errorf("%s: %w", k, err))}
+			}
+			entry.value = expanded
+		}
+
+		name := strings.TrimPrefix(k, spec.key)
+		if err := setMapEntry(result, spec.keyParser, spec.elemParser, name, entry.value); err != nil {
+			return &ParseError{spec.fieldError(entry.value, entry.provider, fmt.Errorf("%s: %w", k, err))}
+		}
+
+		if state.report != nil {
+			entrySpec := spec
+			entrySpec.path, entrySpec.key = spec.path+"["+name+"]", k
+			c.recordSource(state.ctx, state.report, entrySpec, Source{Provider: entry.provider})
+		}
+	}
+
+	field.Set(result)
+
+	return spec.validate(result, "", "")
+}
+
+// providedValue is a value along with the name of the provider that supplied it
+type providedValue struct {
+	value    string
+	provider string
+}
+
+// getPrefixedValues collects non-empty values of every key starting with the key of the field from the value providers
+// that implement KeyLister. Providers added first take priority.
+func (c *ConfigManager) getPrefixedValues(ctx context.Context, spec fieldSpec) (map[string]providedValue, error) {
+	entries := make(map[string]providedValue)
+	for _, p := range c.valueProviders {
+		lister, ok := underlyingProvider(p).(KeyLister)
+		if !ok {
+			continue
+		}
+
+		for _, k := range lister.Keys() {
+			if !strings.HasPrefix(k, spec.key) || k == spec.key {
+				continue
+			}
+			if _, ok := entries[k]; ok {
+				continue
+			}
+
+			value, found, err := p.Lookup(ctx, k)
+			if err != nil {
+				return nil, &ProviderError{FieldError{Path: spec.path, Key: k, Provider: providerName(p), Err: err}}
+			}
+			if found && value != "" {
+				entries[k] = providedValue{value: value, provider: providerName(p)}
+			}
+		}
+	}
+	return entries, nil
+}
+
+// isStructSlice reports whether t is a slice of structs to be filled from indexed keys
+func isStructSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && isStruct(t.Elem())
+}
+
+// elementScope returns the scope of the i-th element of a slice of structs: keys of its fields are prefixed with KEY_i_
+func (s fieldSpec) elementScope(i int) structScope {
+	return structScope{
+		path:      s.path + "[" + strconv.Itoa(i) + "]",
+		keyPrefix: s.key + "_" + strconv.Itoa(i) + "_",
+		enclosing: s.group.enclosing,
+	}
+}
+
+// unmarshalStructSlice fills a slice of structs from indexed keys, e.g. UPSTREAMS_0_HOST, UPSTREAMS_1_HOST.
+// Elements are discovered from index 0 until an index has none of its keys supplied by the value providers.
+// A slice of a struct type enclosing it, as in a tree, is left untouched, as it would never end.
+func (c *ConfigManager) unmarshalStructSlice(field reflect.Value, spec fieldSpec, state *unmarshalState) error {
+	if spec.key == "" {
+		return &UnsupportedTypeError{FieldError: spec.fieldError("", "", nil), Type: field.Type()}
+	}
+	if spec.group.encloses(field.Type().Elem()) {
+		return nil
+	}
+
+	var (
+		result   = reflect.MakeSlice(field.Type(), 0, 0)
+		elemErrs []error
+	)
+
+	for i := 0; spec.maxCount < 0 || i <= spec.maxCount; i++ {
+		elemState := state.sub()
+		elem := reflect.New(field.Type().Elem()).Elem()
+
+		// Errors are only returned by the collecting sub-state when loading is aborted
+		if err := c.unmarshal(elem, spec.elementScope(i), elemState); err != nil {
+			return err
+		}
+		if !elemState.found {
+			break
+		}
+
+		state.merge(elemState)
+		result = reflect.Append(result, elem)
+		elemErrs = append(elemErrs, elemState.errs.Errors...)
+	}
+
+	count := result.Len()
+	if count == 0 && !spec.allowEmpty && spec.minCount <= 0 {
+		return &MissingValueError{FieldError{Path: spec.path, Key: spec.key}}
+	}
+
+	if count < spec.minCount || (spec.maxCount >= 0 && count > spec.maxCount) {
+		return &ElementCountError{
+			FieldError: FieldError{Path: spec.path, Key: spec.key},
+			Count:      count,
+			Min:        spec.minCount,
+			Max:        spec.maxCount,
+		}
+	}
+
+	if count > 0 {
+		field.Set(result)
+	}
+
+	if len(elemErrs) == 0 {
+		return nil
+	}
+
+	if !state.collectErrors {
+		return unwrapPath(elemErrs[0])
+	}
+	state.errs.Errors = append(state.errs.Errors, elemErrs...)
+
+	return nil
+}
@@ -0,0 +1,418 @@
+package gocfg
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jagerente/gocfg/pkg/parsers"
+	"github.com/Jagerente/gocfg/pkg/values"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MapFields(t *testing.T) {
+	type TestConfig struct {
+		Labels    map[string]string        `env:"MAP_LABELS"`
+		Weights   map[string]float64       `env:"MAP_WEIGHTS,sep=;,kvsep=="`
+		Timeouts  map[string]time.Duration `env:"MAP_TIMEOUTS" default:"read:1s,write:2s"`
+		Empty     map[string]int           `env:"MAP_EMPTY,omitempty"`
+		IntKeyMap map[int]bool             `env:"MAP_INT_KEYS"`
+	}
+
+	_ = os.Setenv("MAP_LABELS", "team:core, tier:1")
+	_ = os.Setenv("MAP_WEIGHTS", "a=0.5;b=1.5")
+	_ = os.Unsetenv("MAP_TIMEOUTS")
+	_ = os.Unsetenv("MAP_EMPTY")
+	_ = os.Setenv("MAP_INT_KEYS", "1:true,2:false")
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "core", "tier": "1"}, cfg.Labels)
+	assert.Equal(t, map[string]float64{"a": 0.5, "b": 1.5}, cfg.Weights)
+	assert.Equal(t, map[string]time.Duration{"read": time.Second, "write": 2 * time.Second}, cfg.Timeouts)
+	assert.Nil(t, cfg.Empty)
+	assert.Equal(t, map[int]bool{1: true, 2: false}, cfg.IntKeyMap)
+}
+
+func Test_MapFields_Invalid(t *testing.T) {
+	t.Run("missing separator", func(t *testing.T) {
+		type TestConfig struct {
+			Labels map[string]string `env:"MAP_INVALID_LABELS"`
+		}
+
+		_ = os.Setenv("MAP_INVALID_LABELS", "team:core,tier")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Contains(t, err.Error(), `entry 1: missing key/value separator ":"`)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		type TestConfig struct {
+			Ports map[string]uint16 `env:"MAP_INVALID_PORTS"`
+		}
+
+		_ = os.Setenv("MAP_INVALID_PORTS", "http:80,https:invalid")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Contains(t, err.Error(), `entry 1: invalid value for key "https"`)
+	})
+
+	t.Run("unsupported value type", func(t *testing.T) {
+		type TestConfig struct {
+			Values map[string]complex64 `env:"MAP_UNSUPPORTED"`
+		}
+
+		_ = os.Setenv("MAP_UNSUPPORTED", "a:1")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var unsupportedErr *UnsupportedTypeError
+		assert.True(t, errors.As(err, &unsupportedErr))
+	})
+}
+
+func Test_PrefixMapFields(t *testing.T) {
+	type TestConfig struct {
+		FeatureFlags map[string]bool `env:"FEATURE_FLAGS_,prefix"`
+		Tenants      map[string]int  `env:"PREFIX_TENANT_,prefix" default:"default:1"`
+		Optional     map[string]int  `env:"PREFIX_OPTIONAL_,prefix,omitempty"`
+	}
+
+	_ = os.Setenv("FEATURE_FLAGS_SEARCH", "true")
+	_ = os.Setenv("FEATURE_FLAGS_BETA", "false")
+	_ = os.Setenv("FEATURE_FLAGS_EMPTY", "")
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"SEARCH": true, "BETA": false}, cfg.FeatureFlags)
+	assert.Equal(t, map[string]int{"default": 1}, cfg.Tenants)
+	assert.Nil(t, cfg.Optional)
+}
+
+func Test_PrefixMapFields_Invalid(t *testing.T) {
+	t.Run("invalid value", func(t *testing.T) {
+		type TestConfig struct {
+			Limits map[string]int `env:"PREFIX_LIMIT_,prefix"`
+		}
+
+		_ = os.Setenv("PREFIX_LIMIT_A", "invalid")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "env", parseErr.Provider)
+		assert.Contains(t, err.Error(), "PREFIX_LIMIT_A")
+	})
+
+	t.Run("not a map", func(t *testing.T) {
+		type TestConfig struct {
+			Limit int `env:"PREFIX_NOT_MAP_,prefix"`
+		}
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var unsupportedErr *UnsupportedTypeError
+		assert.True(t, errors.As(err, &unsupportedErr))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		type TestConfig struct {
+			Limits map[string]int `env:"PREFIX_MISSING_,prefix"`
+		}
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var missingErr *MissingValueError
+		assert.True(t, errors.As(err, &missingErr))
+	})
+}
+
+type upperCaseParserProvider struct{}
+
+func (p *upperCaseParserProvider) Get(field reflect.Value) (func(v string) (interface{}, error), bool) {
+	if field.Kind() != reflect.String {
+		return nil, false
+	}
+	return func(v string) (interface{}, error) {
+		return strings.ToUpper(v), nil
+	}, true
+}
+
+func Test_SliceFields(t *testing.T) {
+	type TestConfig struct {
+		Ports    []uint16        `env:"SLICE_PORTS"`
+		Weights  []float64       `env:"SLICE_WEIGHTS,sep=;"`
+		Backoffs []time.Duration `env:"SLICE_BACKOFFS" default:"1s, 2s, 4s"`
+		Bools    [3]bool         `env:"SLICE_BOOLS"`
+		Strings  []string        `env:"SLICE_STRINGS,sep=|"`
+		Bytes    []byte          `env:"SLICE_BYTES"`
+	}
+
+	_ = os.Setenv("SLICE_PORTS", "80, 443,8080")
+	_ = os.Setenv("SLICE_WEIGHTS", "0.5;1.5")
+	_ = os.Unsetenv("SLICE_BACKOFFS")
+	_ = os.Setenv("SLICE_BOOLS", "true,false,true")
+	_ = os.Setenv("SLICE_STRINGS", "a,b|c")
+	_ = os.Setenv("SLICE_BYTES", "a,b")
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{80, 443, 8080}, cfg.Ports)
+	assert.Equal(t, []float64{0.5, 1.5}, cfg.Weights)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, cfg.Backoffs)
+	assert.Equal(t, [3]bool{true, false, true}, cfg.Bools)
+	assert.Equal(t, []string{"a,b", "c"}, cfg.Strings)
+	assert.Equal(t, []byte("a,b"), cfg.Bytes)
+}
+
+func Test_SliceFields_IntElements(t *testing.T) {
+	type TestConfig struct {
+		Ints []int `env:"SLICE_INTS,sep=;"`
+	}
+
+	_ = os.Setenv("SLICE_INTS", "3000000000; -3000000000;1")
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3000000000, -3000000000, 1}, cfg.Ints)
+}
+
+type doublingIntParserProvider struct{}
+
+func (p *doublingIntParserProvider) Get(field reflect.Value) (func(v string) (interface{}, error), bool) {
+	if field.Kind() != reflect.Int {
+		return nil, false
+	}
+	return func(v string) (interface{}, error) {
+		n, err := strconv.Atoi(v)
+		return n * 2, err
+	}, true
+}
+
+func Test_SliceFields_CustomElementParserProvider(t *testing.T) {
+	type TestConfig struct {
+		Int  int   `env:"SLICE_DOUBLED_INT"`
+		Ints []int `env:"SLICE_DOUBLED_INTS"`
+	}
+
+	_ = os.Setenv("SLICE_DOUBLED_INT", "1")
+	_ = os.Setenv("SLICE_DOUBLED_INTS", "1,2")
+
+	cfg := new(TestConfig)
+	err := NewDefault().AddParserProviders(&doublingIntParserProvider{}).Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cfg.Int)
+	assert.Equal(t, []int{2, 4}, cfg.Ints)
+}
+
+func Test_DefaultParserProvider_Slices(t *testing.T) {
+	provider := parsers.NewDefaultParserProvider()
+
+	parser, ok := provider.Get(reflect.ValueOf([]string{}))
+	assert.True(t, ok)
+	value, err := parser("a,b")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, value)
+
+	parser, ok = provider.Get(reflect.ValueOf([]int{}))
+	assert.True(t, ok)
+	value, err = parser("3000000000, 1")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3000000000, 1}, value)
+}
+
+func Test_SliceFields_CustomParserProvider(t *testing.T) {
+	type TestConfig struct {
+		Names []string `env:"SLICE_CUSTOM_NAMES"`
+	}
+
+	_ = os.Setenv("SLICE_CUSTOM_NAMES", "alice,bob")
+
+	cfg := new(TestConfig)
+	err := NewEmpty().
+		AddParserProviders(&upperCaseParserProvider{}).
+		AddValueProviders(values.NewEnvProvider()).
+		Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ALICE", "BOB"}, cfg.Names)
+}
+
+func Test_SliceFields_Invalid(t *testing.T) {
+	t.Run("invalid element", func(t *testing.T) {
+		type TestConfig struct {
+			Ports []uint16 `env:"SLICE_INVALID_PORTS"`
+		}
+
+		_ = os.Setenv("SLICE_INVALID_PORTS", "80,443,invalid")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Contains(t, err.Error(), "failed to parse SLICE_INVALID_PORTS: element 2")
+	})
+
+	t.Run("array length", func(t *testing.T) {
+		type TestConfig struct {
+			Pair [2]int `env:"SLICE_INVALID_PAIR"`
+		}
+
+		_ = os.Setenv("SLICE_INVALID_PAIR", "1,2,3")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "expected 2 elements, got 3")
+	})
+
+	t.Run("unsupported element type", func(t *testing.T) {
+		type TestConfig struct {
+			Values []complex64 `env:"SLICE_UNSUPPORTED"`
+		}
+
+		_ = os.Setenv("SLICE_UNSUPPORTED", "1,2")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var unsupportedErr *UnsupportedTypeError
+		assert.True(t, errors.As(err, &unsupportedErr))
+	})
+}
+
+func Test_StructSliceFields(t *testing.T) {
+	type UpstreamConfig struct {
+		Host   string `env:"HOST"`
+		Port   uint16 `env:"PORT" default:"80"`
+		Weight int    `env:"WEIGHT,omitempty"`
+	}
+
+	type TestConfig struct {
+		Upstreams []UpstreamConfig `env:"STRUCT_SLICE_UPSTREAMS"`
+		Optional  []UpstreamConfig `env:"STRUCT_SLICE_OPTIONAL,omitempty"`
+	}
+
+	_ = os.Setenv("STRUCT_SLICE_UPSTREAMS_0_HOST", "first")
+	_ = os.Setenv("STRUCT_SLICE_UPSTREAMS_0_PORT", "8080")
+	_ = os.Setenv("STRUCT_SLICE_UPSTREAMS_1_HOST", "second")
+	_ = os.Setenv("STRUCT_SLICE_UPSTREAMS_1_WEIGHT", "3")
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []UpstreamConfig{
+		{Host: "first", Port: 8080},
+		{Host: "second", Port: 80, Weight: 3},
+	}, cfg.Upstreams)
+	assert.Nil(t, cfg.Optional)
+}
+
+func Test_StructSliceFields_Invalid(t *testing.T) {
+	type UpstreamConfig struct {
+		Host string `env:"HOST"`
+		Port uint16 `env:"PORT,omitempty"`
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		type TestConfig struct {
+			Upstreams []UpstreamConfig `env:"STRUCT_SLICE_MISSING"`
+		}
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var missingErr *MissingValueError
+		assert.True(t, errors.As(err, &missingErr))
+		assert.Equal(t, "STRUCT_SLICE_MISSING", missingErr.Key)
+	})
+
+	t.Run("invalid element", func(t *testing.T) {
+		type TestConfig struct {
+			Upstreams []UpstreamConfig `env:"STRUCT_SLICE_INVALID"`
+		}
+
+		_ = os.Setenv("STRUCT_SLICE_INVALID_0_HOST", "first")
+		_ = os.Setenv("STRUCT_SLICE_INVALID_1_PORT", "invalid")
+
+		err := NewDefault().CollectErrors().Unmarshal(new(TestConfig))
+
+		var multiErr *MultiError
+		assert.True(t, errors.As(err, &multiErr))
+		assert.Len(t, multiErr.Errors, 2)
+		assert.Contains(t, multiErr.Errors[0].Error(), "Upstreams[1].Host: STRUCT_SLICE_INVALID_1_HOST cannot be empty")
+		assert.Contains(t, multiErr.Errors[1].Error(), "Upstreams[1].Port: failed to parse STRUCT_SLICE_INVALID_1_PORT")
+	})
+
+	t.Run("element count", func(t *testing.T) {
+		type TestConfig struct {
+			TooFew  []UpstreamConfig `env:"STRUCT_SLICE_FEW,min=2"`
+			TooMany []UpstreamConfig `env:"STRUCT_SLICE_MANY,max=1"`
+		}
+
+		_ = os.Setenv("STRUCT_SLICE_FEW_0_HOST", "first")
+		_ = os.Setenv("STRUCT_SLICE_MANY_0_HOST", "first")
+		_ = os.Setenv("STRUCT_SLICE_MANY_1_HOST", "second")
+
+		err := NewDefault().CollectErrors().Unmarshal(new(TestConfig))
+
+		var multiErr *MultiError
+		assert.True(t, errors.As(err, &multiErr))
+		assert.Len(t, multiErr.Errors, 2)
+
+		var countErr *ElementCountError
+		assert.True(t, errors.As(multiErr.Errors[0], &countErr))
+		assert.Equal(t, 1, countErr.Count)
+		assert.Equal(t, "STRUCT_SLICE_FEW must have at least 2 elements, got 1", countErr.Error())
+		assert.True(t, errors.As(multiErr.Errors[1], &countErr))
+		assert.Equal(t, "STRUCT_SLICE_MANY must have between 0 and 1 elements, got 2", countErr.Error())
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		type TestConfig struct {
+			Upstreams []UpstreamConfig
+		}
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var unsupportedErr *UnsupportedTypeError
+		assert.True(t, errors.As(err, &unsupportedErr))
+	})
+}
+
+func Test_parseDocGroup_StructSlice(t *testing.T) {
+	type UpstreamConfig struct {
+		Host string `env:"HOST"`
+	}
+
+	type TestConfig struct {
+		Upstreams []UpstreamConfig `env:"UPSTREAMS" title:"Upstreams"`
+	}
+
+	docGroup := NewDoc()
+	NewEmpty().parseDocGroup(docGroup, new(TestConfig))
+
+	assert.Len(t, docGroup.Groups, 1)
+	assert.True(t, docGroup.Groups[0].Indexed)
+	assert.Equal(t, "Upstreams", docGroup.Groups[0].Title)
+	assert.Equal(t, "UPSTREAMS_0_HOST", docGroup.Groups[0].Fields[0].Key)
+}
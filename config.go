@@ -1,10 +1,13 @@
 package gocfg
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/Jagerente/gocfg/pkg/parsers"
 	"github.com/Jagerente/gocfg/pkg/values"
@@ -18,6 +21,56 @@ const (
 	structAllowEmptyTag  = "omitempty"
 	structDescriptionTag = "description"
 	structTitleTag       = "title"
+	structSecretTag      = "secret"
+	structPrefixTag      = "envPrefix"
+	structValidateTag    = "validate"
+)
+
+// Options recognized after the key in the key tag, e.g. `env:"LABELS,sep=;,kvsep=="`.
+// The omitempty and secret options are named after the structAllowEmptyTag and structSecretTag tags.
+const (
+	tagOptionPrefix            = "prefix"
+	tagOptionSeparator         = "sep"
+	tagOptionKeyValueSeparator = "kvsep"
+	tagOptionMinCount          = "min"
+	tagOptionMaxCount          = "max"
+	tagOptionFile              = "file"
+	tagOptionExpand            = "expand"
+	tagOptionEmpty             = "empty"
+	tagOptionRequired          = "required"
+	tagOptionUnset             = "unset"
+	// tagOptionNotEmpty is a shorthand for empty=reject
+	tagOptionNotEmpty = "notEmpty"
+	// tagOptionSensitive is an alias of the secret option
+	tagOptionSensitive = "sensitive"
+)
+
+// Values of the empty option, deciding how a key explicitly set to an empty value is handled, e.g. `env:"FEATURE_X,empty=accept"`
+const (
+	// emptyValueUnset treats the key as unset, so lower-priority providers and the default are used
+	emptyValueUnset = "unset"
+	// emptyValueAccept sets the field to its zero value
+	emptyValueAccept = "accept"
+	// emptyValueReject fails with an *EmptyValueError
+	emptyValueReject = "reject"
+)
+
+const (
+	defaultSeparator         = ","
+	defaultKeyValueSeparator = ":"
+)
+
+const (
+	// skipKey in the key tag excludes a field from loading and documentation
+	skipKey = "-"
+	// defaultProviderName is reported as the provider of values taken from the default tag
+	defaultProviderName = "default"
+	// redactedValue replaces values of secret fields in errors, logs and dumps
+	redactedValue = "****"
+	// fileKeySuffix is appended to a key to look up the path of a file holding its value
+	fileKeySuffix = "_FILE"
+	// aliasSeparator separates deprecated fallback keys from the key in the key tag, e.g. `env:"REDIS_PASSWORD|REDIS_PASS"`
+	aliasSeparator = "|"
 )
 
 // ValueProvider defines the interface for retrieving values based on keys
@@ -42,25 +95,48 @@ type ConfigManager struct {
 	structExampleTag     string
 	structAllowEmptyTag  string
 	parserProviders      []ParserProvider
-	valueProviders       []ValueProvider
+	valueProviders       []ContextValueProvider
 	useDefaults          bool
 	forceDefaults        bool
 	structDescriptionTag string
 	structTitleTag       string
+	structSecretTag      string
+	structPrefixTag      string
+	structValidateTag    string
+	collectErrors        bool
+	namingStrategy       NamingStrategy
+	logger               Logger
+	secretMask           SecretMask
+	fileIndirection      bool
+	expand               bool
+	// structRequiredTag, structSeparatorTag, structKeyValueSeparatorTag, structSplitWordsTag and structIgnoredTag
+	// are disabled when empty
+	structRequiredTag          string
+	structSeparatorTag         string
+	structKeyValueSeparatorTag string
+	structSplitWordsTag        string
+	structIgnoredTag           string
+	upperCaseKeys              bool
+	optionalByDefault          bool
+	prefixNestedKeys           bool
+	// warnedAliases holds the deprecated keys already warned about, so each is logged once
+	warnedAliases *sync.Map
+	// plans caches the compiled *typePlan of every loaded struct type by its reflect.Type
+	plans *sync.Map
+	// immutable is set for managers created by New and With, whose settings cannot be changed
+	immutable bool
 }
 
 // NewEmpty creates a new ConfigManager instance with default tags and empty providers
 func NewEmpty() *ConfigManager {
-	return &ConfigManager{
-		structKeyTag:         structKeyTag,
-		structDefaultTag:     structDefaultTag,
-		structExampleTag:     structExampleTag,
-		structAllowEmptyTag:  structAllowEmptyTag,
-		structDescriptionTag: structDescriptionTag,
-		structTitleTag:       structTitleTag,
-		parserProviders:      make([]ParserProvider, 0),
-		valueProviders:       make([]ValueProvider, 0),
+	c := &ConfigManager{
+		parserProviders: make([]ParserProvider, 0),
+		valueProviders:  make([]ContextValueProvider, 0),
+		logger:          stdLogger{},
+		warnedAliases:   new(sync.Map),
+		plans:           new(sync.Map),
 	}
+	return c.UseTagNames(DefaultTagNames())
 }
 
 // NewDefault creates a new ConfigManager instance with default tags, default parser, and environment value provider
@@ -74,38 +150,135 @@ func NewDefault() *ConfigManager {
 
 // AddParserProviders adds parser providers to the ConfigManager instance, with higher priority for the providers added first.
 // Which means second provider's result will not overwrite the first providers' result.
+// The default parser providers are the exception: they are tried after all others and after the unmarshaler
+// interfaces implemented by the field type, wherever they were added.
 func (c *ConfigManager) AddParserProviders(provider ...ParserProvider) *ConfigManager {
+	c.checkMutable()
 	for _, p := range provider {
 		c.parserProviders = append(c.parserProviders, p)
 	}
+	c.resetPlans()
 	return c
 }
 
 // AddValueProviders adds value providers to the Config instance, with higher priority for the providers added first.
 // Which means second provider's result will not overwrite the first provider's result.
 func (c *ConfigManager) AddValueProviders(providers ...ValueProvider) *ConfigManager {
+	c.checkMutable()
 	for _, p := range providers {
-		c.valueProviders = append(c.valueProviders, p)
+		c.valueProviders = append(c.valueProviders, AdaptValueProvider(p))
 	}
 	return c
 }
 
+// AddContextValueProviders adds value providers that can report failures, with the same priority rules as AddValueProviders.
+// Both kinds of providers share one chain, in the order they were added.
+func (c *ConfigManager) AddContextValueProviders(providers ...ContextValueProvider) *ConfigManager {
+	c.checkMutable()
+	c.valueProviders = append(c.valueProviders, providers...)
+	return c
+}
+
 // UseDefaults enables the use of default values during the configuration process.
 func (c *ConfigManager) UseDefaults() *ConfigManager {
+	c.checkMutable()
 	c.useDefaults = true
 	return c
 }
 
 // ForceDefaults enables the use of default values even when a value is provided
 func (c *ConfigManager) ForceDefaults() *ConfigManager {
+	c.checkMutable()
 	c.useDefaults = true
 	c.forceDefaults = true
 	return c
 }
 
+// CollectErrors makes Unmarshal walk the whole structure and return every field error at once
+// as a *MultiError instead of stopping at the first one.
+func (c *ConfigManager) CollectErrors() *ConfigManager {
+	c.checkMutable()
+	c.collectErrors = true
+	return c
+}
+
+// UseNamingStrategy derives keys of fields without a key tag from their Go field path using the strategy,
+// e.g. ScreamingSnakeCase turns RedisConfig.MaxIdleConns into REDIS_CONFIG_MAX_IDLE_CONNS.
+// Nested structs tagged with envPrefix start a new path, so their keys are the prefix followed by the derived name.
+func (c *ConfigManager) UseNamingStrategy(strategy NamingStrategy) *ConfigManager {
+	c.checkMutable()
+	c.namingStrategy = strategy
+	c.resetPlans()
+	return c
+}
+
+// UseLogger sets the logger receiving messages about the loading process, such as defaults being used.
+// By default, warnings are written to the standard logger of the log package.
+func (c *ConfigManager) UseLogger(logger Logger) *ConfigManager {
+	c.checkMutable()
+	c.logger = logger
+	return c
+}
+
+// Silent discards all messages about the loading process
+func (c *ConfigManager) Silent() *ConfigManager {
+	c.checkMutable()
+	c.logger = nopLogger{}
+	return c
+}
+
+// UseFileIndirection makes every field read its value from the file named by <KEY>_FILE when <KEY> is unset,
+// as done for fields marked with the file option, e.g. `env:"DB_PASSWORD,file"`
+func (c *ConfigManager) UseFileIndirection() *ConfigManager {
+	c.checkMutable()
+	c.fileIndirection = true
+	c.resetPlans()
+	return c
+}
+
+// UseExpansion makes every field expand ${KEY} references in its value or default,
+// as done for fields marked with the expand option, e.g. `env:"DATABASE_URL,expand"`
+func (c *ConfigManager) UseExpansion() *ConfigManager {
+	c.checkMutable()
+	c.expand = true
+	c.resetPlans()
+	return c
+}
+
 // UseCustomKeyTag sets a custom key tag for struct field annotations
 func (c *ConfigManager) UseCustomKeyTag(tag string) *ConfigManager {
+	c.checkMutable()
 	c.structKeyTag = tag
+	c.resetPlans()
+	return c
+}
+
+// UseTagNames replaces the names of all struct tags, e.g. to avoid clashes with tags of other libraries
+// or to read structs written for them. Start from DefaultTagNames, EnvconfigTagNames or CaarlosEnvTagNames:
+//
+//	names := gocfg.DefaultTagNames()
+//	names.Default = "envDefault"
+//	cfgManager.UseTagNames(names)
+func (c *ConfigManager) UseTagNames(names TagNames) *ConfigManager {
+	c.checkMutable()
+	c.structKeyTag = names.Key
+	c.structDefaultTag = names.Default
+	c.structExampleTag = names.Example
+	c.structAllowEmptyTag = names.OmitEmpty
+	c.structDescriptionTag = names.Description
+	c.structTitleTag = names.Title
+	c.structSecretTag = names.Secret
+	c.structPrefixTag = names.Prefix
+	c.structValidateTag = names.Validate
+	c.structRequiredTag = names.Required
+	c.structSeparatorTag = names.Separator
+	c.structKeyValueSeparatorTag = names.KeyValueSeparator
+	c.structSplitWordsTag = names.SplitWords
+	c.structIgnoredTag = names.Ignored
+	c.upperCaseKeys = names.UpperCaseKeys
+	c.optionalByDefault = names.OptionalByDefault
+	c.prefixNestedKeys = names.PrefixNestedKeys
+	c.resetPlans()
 	return c
 }
 
@@ -116,8 +289,62 @@ func (c *ConfigManager) UseCustomKeyTag(tag string) *ConfigManager {
 // The function recursively traverses the fields of the structure and its nested structures,
 // which means structure can contain as much nested structures as you want.
 //
+// The key tag follows the KEY,option,name=value grammar, e.g. `env:"LABELS,omitempty,sep=;"`. Supported options are
+// omitempty, required, notEmpty, secret (or sensitive), file, expand, unset, prefix, sep, kvsep, min, max and empty.
+// Unknown, repeated and malformed options fail with an *InvalidTagError.
+//
 // You may use omitempty tags to allow fields to be empty.
 // If both the parsed value and the default value are empty, the field will be set to the zero value for its type in Go.
+// Fields are required otherwise; the required option states it explicitly and conflicts with omitempty.
+//
+// Nested structs may be tagged with envPrefix (e.g. `envPrefix:"REPLICA_"`) to prepend a prefix to every key inside them,
+// so one struct type can be reused. Prefixes of nested structs compose.
+//
+// Slices of structs are filled from indexed keys: a field tagged `env:"UPSTREAMS,min=1,max=8"` collects
+// UPSTREAMS_0_HOST, UPSTREAMS_1_HOST and so on, until an index has none of its keys set.
+// The optional min and max options bound the number of elements.
+//
+// Pointer fields are allocated only when there is a value to assign, so an omitempty *T field stays nil when unset.
+// Pointer-to-struct groups are allocated on demand; mark them with omitempty (e.g. `env:",omitempty"`)
+// to leave the group nil when none of its keys are set.
+//
+// Fields marked with the secret option (e.g. `env:"DB_PASSWORD,secret"`) have their values redacted in returned errors,
+// including messages of parser errors, in log messages, dumps and generated documentation.
+//
+// Fields marked with the file option (e.g. `env:"DB_PASSWORD,file"`), or every field once UseFileIndirection is set,
+// read their value from the file named by DB_PASSWORD_FILE when DB_PASSWORD is unset, without the trailing newline.
+//
+// Fields marked with the expand option, or every field once UseExpansion is set, expand references to other keys
+// in their value or default, e.g. postgres://${DB_USER}@${DB_HOST:-localhost}/app. References are resolved through
+// the value providers and the defaults of the fields loaded by the referenced keys.
+// ${KEY:-fallback} and ${KEY:?message} handle unset keys, and $$ stands for a literal $.
+//
+// The key tag may list deprecated keys after the key, e.g. `env:"REDIS_PASSWORD|REDIS_PASS"`. They are tried in order
+// when the key is unset; a warning is logged once when one of them is used, and an *AliasConflictError is returned
+// when several of them are set to different values.
+//
+// Keys explicitly set to an empty value are treated as unset by default. Use the empty option to accept them,
+// setting the field to its zero value (e.g. `env:"FEATURE_X,empty=accept"`), or to reject them with an *EmptyValueError.
+// The notEmpty option is a shorthand for empty=reject.
+//
+// Fields marked with the unset option (e.g. `env:"DB_PASSWORD,unset"`) remove their keys from the value providers
+// implementing Unsetter once read, so secrets are not inherited by child processes. Reloads will not see them again.
+//
+// Parsed values are checked against the rules of the validate tag, e.g. `validate:"min=1,max=65535"`.
+// Supported rules are min, max, len, oneof, regexp, url and hostport; see the README for details.
+//
+// Structs in the tree may implement Defaulter, Validator and AfterLoader hooks: SetDefaults runs before the struct is loaded,
+// Validate and then AfterLoad run once all of its fields are filled. Hook errors are returned as *HookError.
+//
+// Struct types are compiled once per ConfigManager into a cached plan of their keys, options, defaults and parsers,
+// shared by concurrent calls; changing the settings of the ConfigManager drops the plans. Invalid tags, unsupported
+// field types and defaults that cannot be parsed are found when compiling and returned by every call,
+// even when the field has a value. Defaults holding ${VAR} references are only parsed when used, after expansion.
+//
+// Field errors are returned as *MissingValueError, *ParseError, *UnsupportedTypeError, *ValidationError
+// or *InvalidTagError and can be inspected with errors.As.
+// By default, Unmarshal returns on the first field error. Use CollectErrors to get a *MultiError
+// listing every missing, unparsable and unsupported field instead.
 //
 // Example:
 //
@@ -143,59 +370,485 @@ func (c *ConfigManager) UseCustomKeyTag(tag string) *ConfigManager {
 //		WithDefaultField		string			`env:"WITH_DEFAULT_FIELD" default:"ave"`
 //	}
 func (c *ConfigManager) Unmarshal(cfg interface{}) error {
-	val := reflect.ValueOf(cfg).Elem()
+	return c.UnmarshalContext(context.Background(), cfg)
+}
+
+// UnmarshalContext works like Unmarshal, passing ctx to the lookups of value providers.
+// A failed lookup, including one failing because ctx is done, aborts loading with a *ProviderError,
+// even when errors are collected.
+func (c *ConfigManager) UnmarshalContext(ctx context.Context, cfg interface{}) error {
+	return c.unmarshalRoot(ctx, cfg, newUnmarshalState(c.collectErrors))
+}
 
-	for i := 0; i < val.NumField(); i++ {
+// unmarshalRoot fills cfg using the given state and returns the collected errors, if any
+func (c *ConfigManager) unmarshalRoot(ctx context.Context, cfg interface{}, state *unmarshalState) error {
+	state.ctx = ctx
+	state.expander = newExpander(ctx, c, reflect.TypeOf(cfg).Elem())
+
+	if err := c.unmarshal(reflect.ValueOf(cfg).Elem(), structScope{}, state); err != nil {
+		return err
+	}
+
+	if len(state.errs.Errors) > 0 {
+		return state.errs
+	}
+
+	return nil
+}
+
+// structScope is the position of a struct in the walked tree
+type structScope struct {
+	// path is the dotted Go field path of the struct relative to the root structure
+	path string
+	// keyPrefix is prepended to every key inside the struct
+	keyPrefix string
+	// names are the field names leading to the struct since the last envPrefix, used to derive missing keys
+	names []string
+	// enclosing are the struct types enclosing the fields of the scope, used to stop at self-referential types
+	enclosing []reflect.Type
+}
+
+// enter returns the scope of the fields of struct type t
+func (s structScope) enter(t reflect.Type) structScope {
+	s.enclosing = append(s.enclosing[:len(s.enclosing):len(s.enclosing)], t)
+	return s
+}
+
+// encloses reports whether struct type t already encloses the scope, so walking into it again would never end
+func (s structScope) encloses(t reflect.Type) bool {
+	for _, enclosing := range s.enclosing {
+		if enclosing == t {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldSpec describes how a single struct field is loaded
+type fieldSpec struct {
+	path         string
+	key          string
+	aliases      []string    // deprecated keys tried in order after key
+	altKey       string      // bare key tried last when the key is prefixed by PrefixNestedKeys
+	group        structScope // scope of the field's own fields, if it is a nested struct
+	allowEmpty   bool
+	secret       bool
+	file         bool // the value may be read from the file named by KEY_FILE
+	expand       bool // references to other keys in the value are expanded
+	unset        bool // the keys are removed from the providers implementing Unsetter once read
+	prefix       bool
+	separator    string
+	kvSeparator  string
+	minCount     int
+	maxCount     int // -1 when unbounded
+	defaultValue string
+	rules        []validationRule
+	tagErr       error // invalid validation rules or options
+	emptyValues  string
+	preset       bool // the field holds a value set by a SetDefaults hook
+	// parser parses values of value fields and defaults of prefix maps, whose entries are parsed by keyParser
+	// and elemParser. It is nil for unsupported types.
+	parser     func(v string) (interface{}, error)
+	keyParser  func(v string) (interface{}, error)
+	elemParser func(v string) (interface{}, error)
+	defaultErr error // the default value cannot be parsed
+}
+
+// unmarshalState carries the state of a single Unmarshal call through the recursive walk
+type unmarshalState struct {
+	ctx           context.Context
+	errs          *MultiError
+	collectErrors bool
+	// found reports whether any value in the walked subtree was supplied by a value provider
+	found bool
+	// report records the sources of loaded values, if requested
+	report *Report
+	// expander resolves references in values, shared by all states of an Unmarshal call
+	expander *expander
+}
+
+func newUnmarshalState(collectErrors bool) *unmarshalState {
+	return &unmarshalState{
+		errs:          new(MultiError),
+		collectErrors: collectErrors,
+	}
+}
+
+// sub returns a state collecting errors of a subtree that may be discarded
+func (s *unmarshalState) sub() *unmarshalState {
+	sub := newUnmarshalState(true)
+	sub.ctx = s.ctx
+	sub.expander = s.expander
+	if s.report != nil {
+		sub.report = new(Report)
+	}
+	return sub
+}
+
+// merge adopts the findings of a kept subtree state, except for its errors
+func (s *unmarshalState) merge(sub *unmarshalState) {
+	s.found = s.found || sub.found
+	if s.report != nil {
+		s.report.fields = append(s.report.fields, sub.report.fields...)
+	}
+}
+
+// isRequired reports whether the field is marked as required, by the required option or by the required tag
+func (c *ConfigManager) isRequired(field reflect.StructField, tag keyTag) (bool, error) {
+	if tag.has(tagOptionRequired) {
+		return true, nil
+	}
+
+	value := field.Tag.Get(c.structRequiredTag)
+	if value == "" {
+		return false, nil
+	}
+
+	required, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s tag %q: must be a boolean", c.structRequiredTag, value)
+	}
+	return required, nil
+}
+
+// unmarshal fills the fields of val found in the given scope, running the lifecycle hooks val implements around it.
+// When error collection is enabled, field errors are appended to the state and nil is returned.
+func (c *ConfigManager) unmarshal(val reflect.Value, scope structScope, state *unmarshalState) error {
+	preset := callSetDefaults(val)
+
+	errCount := len(state.errs.Errors)
+	if err := c.unmarshalFields(val, scope, preset, state); err != nil {
+		return err
+	}
+
+	// Hooks would only see a partially filled struct
+	if len(state.errs.Errors) > errCount {
+		return nil
+	}
+
+	if err := callAfterFillHooks(val, scope.path); err != nil {
+		if !state.collectErrors {
+			return err
+		}
+		state.errs.Errors = append(state.errs.Errors, err)
+	}
+
+	return nil
+}
+
+// unmarshalFields fills the fields of val found in the given scope.
+// When preset is true, fields already holding a non-zero value are kept if no value or default is found for them.
+func (c *ConfigManager) unmarshalFields(val reflect.Value, scope structScope, preset bool, state *unmarshalState) error {
+	scope = scope.enter(val.Type())
+	for _, plan := range c.typePlan(val.Type()).fields {
 		var (
-			field        = val.Field(i)
-			tag          = val.Type().Field(i).Tag.Get(c.structKeyTag)
-			key          = strings.Split(tag, ",")[0]
-			allowEmpty   = strings.Contains(tag, c.structAllowEmptyTag)
-			defaultValue = val.Type().Field(i).Tag.Get(c.structDefaultTag)
+			field = val.Field(plan.index)
+			spec  = c.specInScope(plan, scope)
 		)
 
-		if field.Kind() == reflect.Struct {
-			if err := c.Unmarshal(field.Addr().Interface()); err != nil {
-				return fmt.Errorf("failed to parse %s: %w", val.Type().Field(i).Name, err)
+		spec.preset = preset && !field.IsZero()
+
+		err := c.compileError(plan, spec, field.Type())
+		if err == nil {
+			switch plan.kind {
+			case structField:
+				if err := c.unmarshal(field, spec.group, state); err != nil {
+					return fmt.Errorf("failed to parse %s: %w", plan.name, err)
+				}
+			case structPtrField:
+				if err := c.unmarshalStructPtr(field, spec, state); err != nil {
+					return fmt.Errorf("failed to parse %s: %w", plan.name, err)
+				}
+			case structSliceField:
+				err = c.unmarshalStructSlice(field, spec, state)
+			case prefixMapField:
+				err = c.unmarshalPrefixMap(field, spec, state)
+			default:
+				err = c.unmarshalField(field, spec, state)
 			}
-			continue
 		}
 
-		var value string
-		if !c.forceDefaults {
-			value = c.getValue(key)
+		if err != nil {
+			if _, abort := err.(*ProviderError); abort || !state.collectErrors {
+				return err
+			}
+			state.errs.add(spec.path, err)
 		}
+	}
 
-		if allowEmpty && value == "" && defaultValue == "" {
-			continue
+	return nil
+}
+
+// unmarshalStructPtr fills a pointer-to-struct group, allocating it on demand.
+// A group marked with omitempty is left untouched when none of its keys are supplied by the value providers.
+// A group of a struct type enclosing it, as in a linked list, is left untouched too, as it would never end.
+func (c *ConfigManager) unmarshalStructPtr(field reflect.Value, spec fieldSpec, state *unmarshalState) error {
+	if spec.group.encloses(field.Type().Elem()) {
+		return nil
+	}
+
+	target := field
+	if field.IsNil() {
+		target = reflect.New(field.Type().Elem())
+	}
+
+	if !spec.allowEmpty {
+		if err := c.unmarshal(target.Elem(), spec.group, state); err != nil {
+			return err
 		}
+		field.Set(target)
+		return nil
+	}
+
+	// Errors are only returned by the collecting sub-state when loading is aborted
+	groupState := state.sub()
+	if err := c.unmarshal(target.Elem(), spec.group, groupState); err != nil {
+		return err
+	}
+	if !groupState.found {
+		return nil
+	}
+
+	field.Set(target)
+	state.merge(groupState)
 
-		if !allowEmpty && value == "" && (defaultValue == "" || !c.useDefaults) {
-			return fmt.Errorf("%s cannot be empty", key)
+	if len(groupState.errs.Errors) == 0 {
+		return nil
+	}
+
+	if !state.collectErrors {
+		return unwrapPath(groupState.errs.Errors[0])
+	}
+	state.errs.Errors = append(state.errs.Errors, groupState.errs.Errors...)
+
+	return nil
+}
+
+// unmarshalField resolves the value for a single non-struct field and assigns it.
+// Pointer fields are allocated only when there is a value to assign.
+func (c *ConfigManager) unmarshalField(field reflect.Value, spec fieldSpec, state *unmarshalState) error {
+	if spec.unset {
+		keys := append([]string{spec.key, spec.key + fileKeySuffix}, spec.aliases...)
+		if spec.altKey != "" {
+			keys = append(keys, spec.altKey)
 		}
+		defer c.unsetKeys(spec, keys...)
+	}
 
-		parser, ok := c.getParser(field)
-		if !ok {
-			return fmt.Errorf("failed to get parser for %s: unsupported", key)
+	var (
+		value, provider, file string
+		present               bool
+		err                   error
+	)
+	if !c.forceDefaults {
+		var key string
+		if key, value, provider, present, err = c.lookupField(state.ctx, spec); err != nil {
+			return err
 		}
 
-		if (value == "" && c.useDefaults) || c.forceDefaults {
-			if !c.forceDefaults {
-				log.Printf("WARNING: value for %s not found, using default value: %s", key, defaultValue)
+		// Errors and reports name the deprecated or bare key when it supplied the value
+		if present && key != spec.key {
+			if key != spec.altKey {
+				c.warnDeprecatedKey(spec, key, provider)
 			}
+			spec.key = key
+		}
+	}
+
+	if present && value == "" {
+		return c.unmarshalEmptyValue(field, spec, provider, state)
+	}
 
-			value = defaultValue
+	if value == "" && spec.file && spec.key != "" && !c.forceDefaults {
+		if value, provider, file, err = c.getFileValue(state.ctx, spec); err != nil {
+			return err
 		}
+	}
+
+	if value != "" {
+		state.found = true
+	}
 
-		v, err := parser(value)
+	if spec.allowEmpty && value == "" && spec.defaultValue == "" {
+		return nil
+	}
+
+	if !spec.allowEmpty && value == "" && (spec.defaultValue == "" || !c.useDefaults) {
+		if spec.preset {
+			return nil
+		}
+		return &MissingValueError{FieldError{Path: spec.path, Key: spec.key}}
+	}
+
+	if (value == "" && c.useDefaults) || c.forceDefaults {
+		if c.forceDefaults {
+			c.logger.Debug("using forced default value", spec.logAttrs(defaultProviderName, "default", spec.redact(spec.defaultValue))...)
+		} else {
+			c.logger.Warn("value not found, using default value", spec.logAttrs(defaultProviderName, "default", spec.redact(spec.defaultValue))...)
+		}
+
+		value, provider = spec.defaultValue, defaultProviderName
+	} else if file != "" {
+		c.logger.Debug("value loaded", spec.logAttrs(provider, "file", file)...)
+	} else {
+		c.logger.Debug("value loaded", spec.logAttrs(provider)...)
+	}
+
+	if state.report != nil {
+		c.recordSource(state.ctx, state.report, spec, Source{Provider: provider, File: file})
+	}
+
+	if spec.expand {
+		expanded, err := state.expander.expand(value, spec.path, spec.key)
+		if providerErr, ok := err.(*ProviderError); ok {
+			return providerErr
+		}
 		if err != nil {
-			return fmt.Errorf("failed to parse %s: %w", key, err)
+			return &ExpansionError{spec.fieldError(value, provider, err)}
 		}
+		value = expanded
+	}
 
-		field.Set(reflect.ValueOf(v).Convert(field.Type()))
+	target := field
+	if field.Kind() == reflect.Ptr {
+		target = reflect.New(field.Type().Elem()).Elem()
 	}
 
-	return nil
+	v, err := spec.parser(value)
+	if err != nil {
+		return &ParseError{spec.fieldError(value, provider, err)}
+	}
+
+	target.Set(reflect.ValueOf(v).Convert(target.Type()))
+
+	if field.Kind() == reflect.Ptr {
+		field.Set(target.Addr())
+	}
+
+	return spec.validate(target, value, provider)
+}
+
+// lookupField looks up the value of a field by its key, then by its deprecated aliases, then by its bare key,
+// and returns the key that supplied the value. It fails with an *AliasConflictError when several of these keys are set to different values.
+func (c *ConfigManager) lookupField(ctx context.Context, spec fieldSpec) (key, value, provider string, present bool, err error) {
+	stopAtEmpty := spec.emptyValues != emptyValueUnset
+
+	key = spec.key
+	value, provider, present, err = c.lookupValue(ctx, spec.path, key, stopAtEmpty)
+	if err != nil {
+		return "", "", "", false, err
+	}
+
+	for _, alias := range spec.aliases {
+		aliasValue, aliasProvider, aliasPresent, err := c.lookupValue(ctx, spec.path, alias, stopAtEmpty)
+		if err != nil {
+			return "", "", "", false, err
+		}
+
+		switch {
+		case !aliasPresent:
+		case !present:
+			key, value, provider, present = alias, aliasValue, aliasProvider, true
+		case aliasValue != value:
+			return "", "", "", false, &AliasConflictError{
+				FieldError: FieldError{Path: spec.path, Key: key, Provider: provider},
+				Alias:      alias,
+			}
+		}
+	}
+
+	if !present && spec.altKey != "" {
+		if value, provider, present, err = c.lookupValue(ctx, spec.path, spec.altKey, stopAtEmpty); err != nil {
+			return "", "", "", false, err
+		}
+		if present {
+			key = spec.altKey
+		}
+	}
+
+	return key, value, provider, present, nil
+}
+
+// warnDeprecatedKey logs that a deprecated alias supplied the value of a field, once per alias
+func (c *ConfigManager) warnDeprecatedKey(spec fieldSpec, alias, provider string) {
+	if _, warned := c.warnedAliases.LoadOrStore(alias, true); warned {
+		return
+	}
+	c.logger.Warn("deprecated key used, rename it", spec.logAttrs(provider, "deprecated_key", alias)...)
+}
+
+// unmarshalEmptyValue handles a key explicitly set to an empty value by the provider,
+// either rejecting it or setting the field to its zero value, as chosen by the empty option
+func (c *ConfigManager) unmarshalEmptyValue(field reflect.Value, spec fieldSpec, provider string, state *unmarshalState) error {
+	if spec.emptyValues == emptyValueReject {
+		return &EmptyValueError{spec.fieldError("", provider, nil)}
+	}
+
+	state.found = true
+	c.logger.Debug("empty value loaded", spec.logAttrs(provider)...)
+
+	if state.report != nil {
+		c.recordSource(state.ctx, state.report, spec, Source{Provider: provider})
+	}
+
+	target := field
+	if field.Kind() == reflect.Ptr {
+		target = reflect.New(field.Type().Elem()).Elem()
+	}
+
+	target.Set(reflect.Zero(target.Type()))
+
+	if field.Kind() == reflect.Ptr {
+		field.Set(target.Addr())
+	}
+
+	return spec.validate(target, "", provider)
+}
+
+// unsetKeys removes the keys of a field marked with the unset option from the value providers implementing Unsetter.
+// Failures are logged, as the value has already been read.
+func (c *ConfigManager) unsetKeys(spec fieldSpec, keys ...string) {
+	for _, p := range c.valueProviders {
+		unsetter, ok := underlyingProvider(p).(Unsetter)
+		if !ok {
+			continue
+		}
+
+		for _, key := range keys {
+			if err := unsetter.Unset(key); err != nil {
+				c.logger.Warn("failed to unset key", spec.logAttrs(providerName(p), "unset_key", key, "error", err)...)
+			}
+		}
+	}
+}
+
+// redact replaces the value of secret fields
+func (s fieldSpec) redact(value string) string {
+	if s.secret {
+		return redactedValue
+	}
+	return value
+}
+
+// logAttrs returns the logger attributes describing the field and the source of its value, followed by extra attributes
+func (s fieldSpec) logAttrs(source string, extra ...interface{}) []interface{} {
+	return append([]interface{}{"key", s.key, "path", s.path, "source", source}, extra...)
+}
+
+// fieldError builds the FieldError details for the field, redacting the value of secret fields,
+// including from the message of err
+func (s fieldSpec) fieldError(value, provider string, err error) FieldError {
+	if s.secret && err != nil && value != "" {
+		err = redactError(err, value, s.separator, s.kvSeparator)
+	}
+
+	return FieldError{
+		Path:     s.path,
+		Key:      s.key,
+		Value:    s.redact(value),
+		Provider: provider,
+		Err:      err,
+	}
 }
 
 func (c *ConfigManager) GenerateDocumentation(cfg interface{}, docGen DocGenerator) error {
@@ -211,51 +864,136 @@ func (c *ConfigManager) GenerateDocumentation(cfg interface{}, docGen DocGenerat
 }
 
 func (c *ConfigManager) parseDocGroup(docGroup *DocTree, cfg interface{}) {
-	val := reflect.ValueOf(cfg).Elem()
+	c.parseDocFields(docGroup, reflect.TypeOf(cfg).Elem(), structScope{})
+}
 
-	for i := 0; i < val.NumField(); i++ {
-		var (
-			field        = val.Field(i)
-			tag          = val.Type().Field(i).Tag.Get(c.structKeyTag)
-			key          = strings.Split(tag, ",")[0]
-			allowEmpty   = strings.Contains(tag, c.structAllowEmptyTag)
-			defaultValue = val.Type().Field(i).Tag.Get(c.structDefaultTag)
-			exampleValue = val.Type().Field(i).Tag.Get(c.structExampleTag)
-			description  = val.Type().Field(i).Tag.Get(c.structDescriptionTag)
-			title        = val.Type().Field(i).Tag.Get(c.structTitleTag)
-		)
+// parseDocFields adds the fields of struct type t found in the given scope to the docGroup
+// Groups of struct types enclosing them are left out.
+func (c *ConfigManager) parseDocFields(docGroup *DocTree, t reflect.Type, scope structScope) {
+	scope = scope.enter(t)
+	for _, plan := range c.typePlan(t).fields {
+		spec := c.specInScope(plan, scope)
 
-		if field.Kind() == reflect.Struct {
-			c.parseDocGroup(docGroup.AddGroup(title), field.Addr().Interface())
+		switch {
+		case plan.kind == structField:
+			c.parseDocFields(docGroup.AddGroup(plan.title), plan.typ, spec.group)
+			continue
+		case (plan.kind == structPtrField || plan.kind == structSliceField) && scope.encloses(plan.typ.Elem()):
+			continue
+		case plan.kind == structPtrField:
+			c.parseDocFields(docGroup.AddGroup(plan.title), plan.typ.Elem(), spec.group)
 			continue
+		case plan.kind == structSliceField && spec.key != "":
+			group := docGroup.AddGroup(plan.title)
+			group.Indexed = true
+			c.parseDocFields(group, plan.typ.Elem(), spec.elementScope(0))
+			continue
+		}
+
+		var fileKey string
+		if spec.file && spec.key != "" {
+			fileKey = spec.key + fileKeySuffix
 		}
 
-		docGroup.AddField(&DocField{
-			Key:          key,
-			OmitEmpty:    allowEmpty,
-			Description:  description,
-			DefaultValue: defaultValue,
-			ExampleValue: exampleValue,
+		docField := docGroup.AddField(&DocField{
+			Key:            spec.key,
+			DeprecatedKeys: spec.aliases,
+			FileKey:        fileKey,
+			OmitEmpty:      spec.allowEmpty,
+			Description:    plan.description,
+			DefaultValue:   spec.defaultValue,
+			ExampleValue:   plan.example,
+			Secret:         spec.secret,
 		})
+
+		if spec.secret {
+			docField.DefaultValue, docField.ExampleValue = "", ""
+		}
+
+		valueType := plan.typ
+		if valueType.Kind() == reflect.Ptr {
+			valueType = valueType.Elem()
+		}
+
+		// min and max bound the length of strings, slices and maps rather than their value, which is no range
+		for _, rule := range spec.rules {
+			switch {
+			case rule.name == validateRuleOneOf:
+				docField.AllowedValues = strings.Fields(rule.param)
+			case rule.name == validateRuleMin && !hasLength(valueType):
+				docField.Min = rule.param
+			case rule.name == validateRuleMax && !hasLength(valueType):
+				docField.Max = rule.param
+			}
+		}
 	}
 }
 
-// getValue retrieves the value for a key from registered value providers
-func (c *ConfigManager) getValue(key string) string {
-	for _, p := range c.valueProviders {
-		if value := p.Get(key); value != "" {
-			return value
+// prefixKey prepends the prefix of the enclosing structs to a key. Empty keys stay empty.
+func prefixKey(prefix, key string) string {
+	if key == "" {
+		return ""
+	}
+	return prefix + key
+}
+
+// joinFieldPath appends a field name to a dotted Go field path
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// isStruct reports whether t is a struct to be walked field by field.
+// Structs implementing one of the supported unmarshaler interfaces are parsed as a single value instead.
+func isStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !implementsUnmarshaler(t)
+}
+
+// isStructPtr reports whether t is a pointer to a struct to be walked field by field
+func isStructPtr(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && isStruct(t.Elem())
+}
+
+// getFileValue reads the value of a field from the file named by its KEY_FILE key, trimming the trailing newline.
+// It returns the provider of the path and the path itself, or empty strings when KEY_FILE is unset.
+func (c *ConfigManager) getFileValue(ctx context.Context, spec fieldSpec) (value, provider, file string, err error) {
+	fileKey := spec.key + fileKeySuffix
+
+	file, provider, err = c.getValue(ctx, spec.path, fileKey)
+	if err != nil || file == "" {
+		return "", "", "", err
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", "", "", &FileError{
+			FieldError: FieldError{Path: spec.path, Key: fileKey, Value: file, Provider: provider, Err: err},
+			File:       file,
 		}
 	}
-	return ""
+
+	value = strings.TrimSuffix(strings.TrimSuffix(string(content), "\n"), "\r")
+
+	return value, provider, file, nil
 }
 
-// getParser retrieves the parser function for a field from registered parser providers
-func (c *ConfigManager) getParser(field reflect.Value) (parser func(v string) (interface{}, error), ok bool) {
+// getParser retrieves the parser function for a field from the default parser providers when defaults is true,
+// or from the other registered parser providers otherwise, see getFieldParser for the order in which they are tried
+func (c *ConfigManager) getParser(field reflect.Value, defaults bool) (parser func(v string) (interface{}, error), ok bool) {
 	for _, provider := range c.parserProviders {
+		if isDefaultParserProvider(provider) != defaults {
+			continue
+		}
 		if parser, ok = provider.Get(field); ok {
 			return
 		}
 	}
 	return
 }
+
+func isDefaultParserProvider(provider ParserProvider) bool {
+	_, ok := provider.(*parsers.DefaultParserProvider)
+	return ok
+}
@@ -2,7 +2,10 @@ package gocfg
 
 import (
 	"errors"
+	"log"
 	"os"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -460,6 +463,19 @@ func Test_GenerateDocumentation(t *testing.T) {
 	assert.Equal(t, "Description for Nested BoolField", mockDocGenerator.GeneratedDoc.Groups[0].Fields[0].Description)
 }
 
+func Test_GenerateDocumentation_Secret(t *testing.T) {
+	type TestConfig struct {
+		Password string `env:"DOC_PASSWORD,secret" default:"hunter2" example:"s3cr3t"`
+	}
+
+	mockDocGenerator := &MockDocGenerator{}
+
+	err := NewEmpty().GenerateDocumentation(new(TestConfig), mockDocGenerator)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &DocField{Key: "DOC_PASSWORD", Secret: true}, mockDocGenerator.GeneratedDoc.Fields[0])
+}
+
 func Test_GenerateDocumentation_WithError(t *testing.T) {
 	type TestConfig struct {
 	}
@@ -539,3 +555,671 @@ func Test_parseDocGroup_WithExampleTag(t *testing.T) {
 	assert.Equal(t, "only_default", docGroup.Fields[2].DefaultValue)
 	assert.Equal(t, "", docGroup.Fields[2].ExampleValue)
 }
+
+func Test_CollectErrors(t *testing.T) {
+	type TestConfig struct {
+		StringField string `env:"COLLECT_STRING_FIELD"`
+		IntField    int    `env:"COLLECT_INT_FIELD"`
+		StructField struct {
+			NestedField      int        `env:"COLLECT_NESTED_FIELD"`
+			UnsupportedField complex128 `env:"COLLECT_UNSUPPORTED_FIELD"`
+		}
+		ValidField string `env:"COLLECT_VALID_FIELD"`
+	}
+
+	_ = os.Unsetenv("COLLECT_STRING_FIELD")
+	_ = os.Setenv("COLLECT_INT_FIELD", "invalid")
+	_ = os.Unsetenv("COLLECT_NESTED_FIELD")
+	_ = os.Setenv("COLLECT_UNSUPPORTED_FIELD", "value")
+	_ = os.Setenv("COLLECT_VALID_FIELD", "valid")
+
+	cfg := new(TestConfig)
+	cfgManager := NewDefault().CollectErrors()
+	err := cfgManager.Unmarshal(cfg)
+
+	var multiErr *MultiError
+	assert.True(t, errors.As(err, &multiErr))
+	assert.Len(t, multiErr.Errors, 4)
+	assert.Contains(t, multiErr.Errors[0].Error(), "StringField: COLLECT_STRING_FIELD cannot be empty")
+	assert.Contains(t, multiErr.Errors[1].Error(), "IntField: failed to parse COLLECT_INT_FIELD")
+	assert.Contains(t, multiErr.Errors[2].Error(), "StructField.NestedField: COLLECT_NESTED_FIELD cannot be empty")
+	assert.Contains(t, multiErr.Errors[3].Error(), "StructField.UnsupportedField: failed to get parser for COLLECT_UNSUPPORTED_FIELD: unsupported")
+	assert.Contains(t, err.Error(), "4 errors occurred")
+	assert.Equal(t, "valid", cfg.ValidField)
+}
+
+func Test_CollectErrors_NoErrors(t *testing.T) {
+	type TestConfig struct {
+		StringField string `env:"COLLECT_OK_FIELD"`
+	}
+
+	_ = os.Setenv("COLLECT_OK_FIELD", "value")
+
+	cfg := new(TestConfig)
+	err := NewDefault().CollectErrors().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "value", cfg.StringField)
+}
+
+func Test_TypedErrors(t *testing.T) {
+	t.Run("missing value", func(t *testing.T) {
+		type TestConfig struct {
+			RedisConfig struct {
+				RedisHost string `env:"TYPED_REDIS_HOST"`
+			}
+		}
+
+		_ = os.Unsetenv("TYPED_REDIS_HOST")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var missingErr *MissingValueError
+		assert.True(t, errors.As(err, &missingErr))
+		assert.Equal(t, "RedisConfig.RedisHost", missingErr.Path)
+		assert.Equal(t, "TYPED_REDIS_HOST", missingErr.Key)
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		type TestConfig struct {
+			RedisConfig struct {
+				RedisPort uint16 `env:"TYPED_REDIS_PORT"`
+			}
+		}
+
+		_ = os.Setenv("TYPED_REDIS_PORT", "invalid")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "RedisConfig.RedisPort", parseErr.Path)
+		assert.Equal(t, "TYPED_REDIS_PORT", parseErr.Key)
+		assert.Equal(t, "invalid", parseErr.Value)
+		assert.Equal(t, "env", parseErr.Provider)
+		assert.True(t, errors.Is(err, strconv.ErrSyntax))
+	})
+
+	t.Run("parse error from default", func(t *testing.T) {
+		type TestConfig struct {
+			IntField int `env:"TYPED_DEFAULT_INT" default:"invalid"`
+		}
+
+		_ = os.Unsetenv("TYPED_DEFAULT_INT")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "invalid", parseErr.Value)
+		assert.Equal(t, "default", parseErr.Provider)
+	})
+
+	t.Run("secret value is redacted", func(t *testing.T) {
+		type TestConfig struct {
+			Secret int `env:"TYPED_SECRET_INT,secret"`
+		}
+
+		_ = os.Setenv("TYPED_SECRET_INT", "hunter2")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "****", parseErr.Value)
+		assert.NotContains(t, err.Error(), "hunter2")
+		assert.True(t, errors.Is(err, strconv.ErrSyntax))
+	})
+
+	t.Run("secret list element is redacted", func(t *testing.T) {
+		type TestConfig struct {
+			Secrets []int `env:"TYPED_SECRET_INTS,sensitive"`
+		}
+
+		_ = os.Setenv("TYPED_SECRET_INTS", "10, hunter2 ,30")
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		assert.EqualError(t, err, `failed to parse TYPED_SECRET_INTS: element 1: strconv.ParseInt: parsing "****": invalid syntax`)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		type TestConfig struct {
+			Unsupported complex64 `env:"TYPED_UNSUPPORTED"`
+		}
+
+		_ = os.Setenv("TYPED_UNSUPPORTED", "value")
+
+		err := NewDefault().CollectErrors().Unmarshal(new(TestConfig))
+
+		var unsupportedErr *UnsupportedTypeError
+		assert.True(t, errors.As(err, &unsupportedErr))
+		assert.Equal(t, "Unsupported", unsupportedErr.Path)
+		assert.Equal(t, reflect.TypeOf(complex64(0)), unsupportedErr.Type)
+	})
+}
+
+func Test_PointerFields(t *testing.T) {
+	type TestConfig struct {
+		IntPtr      *int           `env:"PTR_INT_FIELD"`
+		DurationPtr *time.Duration `env:"PTR_DURATION_FIELD,omitempty"`
+		StringPtr   *string        `env:"PTR_STRING_FIELD,omitempty"`
+		DefaultPtr  *uint16        `env:"PTR_DEFAULT_FIELD" default:"6379"`
+	}
+
+	_ = os.Setenv("PTR_INT_FIELD", "42")
+	_ = os.Setenv("PTR_DURATION_FIELD", "5s")
+	_ = os.Unsetenv("PTR_STRING_FIELD")
+	_ = os.Unsetenv("PTR_DEFAULT_FIELD")
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, cfg.IntPtr)
+	assert.Equal(t, 42, *cfg.IntPtr)
+	assert.NotNil(t, cfg.DurationPtr)
+	assert.Equal(t, 5*time.Second, *cfg.DurationPtr)
+	assert.Nil(t, cfg.StringPtr)
+	assert.NotNil(t, cfg.DefaultPtr)
+	assert.Equal(t, uint16(6379), *cfg.DefaultPtr)
+}
+
+func Test_PointerFields_Missing(t *testing.T) {
+	type TestConfig struct {
+		IntPtr *int `env:"PTR_MISSING_FIELD"`
+	}
+
+	_ = os.Unsetenv("PTR_MISSING_FIELD")
+
+	err := NewDefault().Unmarshal(new(TestConfig))
+
+	var missingErr *MissingValueError
+	assert.True(t, errors.As(err, &missingErr))
+}
+
+func Test_PointerStructFields(t *testing.T) {
+	type RedisConfig struct {
+		RedisHost string `env:"PTR_REDIS_HOST"`
+		RedisPort uint16 `env:"PTR_REDIS_PORT" default:"6379"`
+	}
+
+	type TestConfig struct {
+		Redis         *RedisConfig
+		OptionalRedis *RedisConfig `env:",omitempty"`
+	}
+
+	t.Run("allocated on demand", func(t *testing.T) {
+		_ = os.Setenv("PTR_REDIS_HOST", "localhost")
+
+		cfg := new(TestConfig)
+		err := NewDefault().Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, cfg.Redis)
+		assert.Equal(t, "localhost", cfg.Redis.RedisHost)
+		assert.Equal(t, uint16(6379), cfg.Redis.RedisPort)
+		assert.NotNil(t, cfg.OptionalRedis)
+		assert.Equal(t, "localhost", cfg.OptionalRedis.RedisHost)
+	})
+
+	t.Run("optional group left nil", func(t *testing.T) {
+		type OptionalConfig struct {
+			Redis *RedisConfig `env:",omitempty"`
+		}
+
+		_ = os.Unsetenv("PTR_REDIS_HOST")
+		_ = os.Unsetenv("PTR_REDIS_PORT")
+
+		cfg := new(OptionalConfig)
+		err := NewDefault().Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.Nil(t, cfg.Redis)
+	})
+
+	t.Run("optional group partially set", func(t *testing.T) {
+		type OptionalConfig struct {
+			Redis *RedisConfig `env:",omitempty"`
+		}
+
+		_ = os.Unsetenv("PTR_REDIS_HOST")
+		_ = os.Setenv("PTR_REDIS_PORT", "6380")
+
+		cfg := new(OptionalConfig)
+		err := NewDefault().Unmarshal(cfg)
+
+		var missingErr *MissingValueError
+		assert.True(t, errors.As(err, &missingErr))
+		assert.Equal(t, "Redis.RedisHost", missingErr.Path)
+		assert.Contains(t, err.Error(), "failed to parse Redis")
+	})
+
+	t.Run("existing pointer is reused", func(t *testing.T) {
+		_ = os.Setenv("PTR_REDIS_HOST", "localhost")
+
+		existing := &RedisConfig{}
+		cfg := &TestConfig{Redis: existing}
+		err := NewDefault().Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.Same(t, existing, cfg.Redis)
+		assert.Equal(t, "localhost", existing.RedisHost)
+	})
+}
+
+type unexportedEmbedded struct {
+	Region string `env:"UNEXPORTED_EMBEDDED_REGION"`
+	zone   string
+}
+
+func Test_UnexportedFields(t *testing.T) {
+	t.Run("third-party struct pointer", func(t *testing.T) {
+		type TestConfig struct {
+			Logger *log.Logger `env:"LOGGER,omitempty"`
+		}
+
+		cfg := new(TestConfig)
+		err := NewDefault().Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.Nil(t, cfg.Logger)
+	})
+
+	t.Run("embedded struct of unexported type", func(t *testing.T) {
+		type TestConfig struct {
+			unexportedEmbedded
+		}
+
+		_ = os.Setenv("UNEXPORTED_EMBEDDED_REGION", "eu")
+		defer func() { _ = os.Unsetenv("UNEXPORTED_EMBEDDED_REGION") }()
+
+		cfg := new(TestConfig)
+		err := NewDefault().Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "eu", cfg.Region)
+
+		dumped := NewDefault().DumpFields(cfg, nil)
+		assert.Len(t, dumped, 1)
+		assert.Equal(t, "UNEXPORTED_EMBEDDED_REGION", dumped[0].Key)
+	})
+}
+
+func Test_parseDocGroup_PointerStruct(t *testing.T) {
+	type Nested struct {
+		BoolField bool `env:"NESTED_BOOL_FIELD"`
+	}
+
+	type TestConfig struct {
+		NestedStruct *Nested `title:"Nested Struct Config"`
+	}
+
+	docGroup := NewDoc()
+	NewEmpty().parseDocGroup(docGroup, new(TestConfig))
+
+	assert.Len(t, docGroup.Groups, 1)
+	assert.Equal(t, "Nested Struct Config", docGroup.Groups[0].Title)
+	assert.Equal(t, "NESTED_BOOL_FIELD", docGroup.Groups[0].Fields[0].Key)
+}
+
+type selfReferentialNode struct {
+	V        int                   `env:"NODE_V,omitempty" default:"1"`
+	Next     *selfReferentialNode  `env:",omitempty"`
+	Children []selfReferentialNode `env:"NODE_CHILDREN,omitempty"`
+}
+
+func Test_SelfReferentialStruct(t *testing.T) {
+	t.Run("unmarshal leaves the pointer nil", func(t *testing.T) {
+		_ = os.Setenv("NODE_V", "2")
+		defer os.Unsetenv("NODE_V")
+
+		cfg := new(selfReferentialNode)
+		err := NewDefault().UseExpansion().Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, cfg.V)
+		assert.Nil(t, cfg.Next)
+		assert.Empty(t, cfg.Children)
+	})
+
+	t.Run("docs leave the group out", func(t *testing.T) {
+		docGroup := NewDoc()
+		NewEmpty().parseDocGroup(docGroup, new(selfReferentialNode))
+
+		assert.Len(t, docGroup.Fields, 1)
+		assert.Equal(t, "NODE_V", docGroup.Fields[0].Key)
+		assert.Empty(t, docGroup.Groups)
+	})
+}
+
+func Test_EnvPrefix(t *testing.T) {
+	type PostgresConfig struct {
+		Host string `env:"DB_HOST"`
+		Port int    `env:"DB_PORT" default:"5432"`
+	}
+
+	type ClusterConfig struct {
+		Primary PostgresConfig  `envPrefix:"PRIMARY_"`
+		Replica *PostgresConfig `envPrefix:"REPLICA_"`
+	}
+
+	type TestConfig struct {
+		Cluster ClusterConfig `envPrefix:"PREFIXED_"`
+		Legacy  PostgresConfig
+	}
+
+	_ = os.Setenv("PREFIXED_PRIMARY_DB_HOST", "primary")
+	_ = os.Setenv("PREFIXED_REPLICA_DB_HOST", "replica")
+	_ = os.Setenv("PREFIXED_REPLICA_DB_PORT", "5433")
+	_ = os.Setenv("DB_HOST", "legacy")
+	_ = os.Unsetenv("PREFIXED_PRIMARY_DB_PORT")
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", cfg.Cluster.Primary.Host)
+	assert.Equal(t, 5432, cfg.Cluster.Primary.Port)
+	assert.Equal(t, "replica", cfg.Cluster.Replica.Host)
+	assert.Equal(t, 5433, cfg.Cluster.Replica.Port)
+	assert.Equal(t, "legacy", cfg.Legacy.Host)
+}
+
+func Test_EnvPrefix_MissingValue(t *testing.T) {
+	type PostgresConfig struct {
+		Host string `env:"DB_HOST"`
+	}
+
+	type TestConfig struct {
+		Replica PostgresConfig `envPrefix:"MISSING_REPLICA_"`
+	}
+
+	err := NewDefault().Unmarshal(new(TestConfig))
+
+	var missingErr *MissingValueError
+	assert.True(t, errors.As(err, &missingErr))
+	assert.Equal(t, "MISSING_REPLICA_DB_HOST", missingErr.Key)
+	assert.Equal(t, "Replica.Host", missingErr.Path)
+}
+
+func Test_parseDocGroup_EnvPrefix(t *testing.T) {
+	type PostgresConfig struct {
+		Host string `env:"DB_HOST"`
+	}
+
+	type ClusterConfig struct {
+		Primary PostgresConfig  `envPrefix:"PRIMARY_" title:"Primary"`
+		Replica *PostgresConfig `envPrefix:"REPLICA_" title:"Replica"`
+	}
+
+	type TestConfig struct {
+		Cluster ClusterConfig `envPrefix:"APP_"`
+	}
+
+	docGroup := NewDoc()
+	NewEmpty().parseDocGroup(docGroup, new(TestConfig))
+
+	assert.Len(t, docGroup.Groups, 1)
+	assert.Len(t, docGroup.Groups[0].Groups, 2)
+	assert.Equal(t, "APP_PRIMARY_DB_HOST", docGroup.Groups[0].Groups[0].Fields[0].Key)
+	assert.Equal(t, "APP_REPLICA_DB_HOST", docGroup.Groups[0].Groups[1].Fields[0].Key)
+}
+
+func Test_FileIndirection(t *testing.T) {
+	type TestConfig struct {
+		Password string `env:"FILE_DB_PASSWORD,file,secret"`
+		User     string `env:"FILE_DB_USER,file"`
+		Port     int    `env:"FILE_DB_PORT" default:"5432"`
+	}
+
+	dir := t.TempDir()
+	passwordFile := dir + "/db_password"
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("hunter2\r\n"), 0o600))
+	portFile := dir + "/db_port"
+	assert.NoError(t, os.WriteFile(portFile, []byte("6432\n"), 0o600))
+
+	_ = os.Unsetenv("FILE_DB_PASSWORD")
+	_ = os.Setenv("FILE_DB_PASSWORD_FILE", passwordFile)
+	_ = os.Setenv("FILE_DB_USER", "admin")
+	_ = os.Setenv("FILE_DB_USER_FILE", dir+"/missing")
+	_ = os.Unsetenv("FILE_DB_PORT")
+	_ = os.Setenv("FILE_DB_PORT_FILE", portFile)
+	defer func() {
+		_ = os.Unsetenv("FILE_DB_PASSWORD_FILE")
+		_ = os.Unsetenv("FILE_DB_USER")
+		_ = os.Unsetenv("FILE_DB_USER_FILE")
+		_ = os.Unsetenv("FILE_DB_PORT_FILE")
+	}()
+
+	t.Run("per field", func(t *testing.T) {
+		cfg := new(TestConfig)
+		report, err := NewDefault().Silent().UnmarshalWithReport(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", cfg.Password)
+		assert.Equal(t, "admin", cfg.User)
+		assert.Equal(t, 5432, cfg.Port)
+
+		password, ok := report.Field("Password")
+		assert.True(t, ok)
+		assert.Equal(t, Source{Provider: "env", File: passwordFile}, password.Source)
+	})
+
+	t.Run("global", func(t *testing.T) {
+		cfg := new(TestConfig)
+		err := NewDefault().Silent().UseFileIndirection().Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 6432, cfg.Port)
+	})
+
+	t.Run("unreadable file", func(t *testing.T) {
+		_ = os.Unsetenv("FILE_DB_USER")
+
+		err := NewDefault().Silent().Unmarshal(new(TestConfig))
+
+		var fileErr *FileError
+		assert.True(t, errors.As(err, &fileErr))
+		assert.Equal(t, "FILE_DB_USER_FILE", fileErr.Key)
+		assert.Equal(t, dir+"/missing", fileErr.File)
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+		assert.Contains(t, err.Error(), dir+"/missing")
+	})
+}
+
+func Test_parseDocGroup_FileKey(t *testing.T) {
+	type TestConfig struct {
+		Password string `env:"DB_PASSWORD,file"`
+		User     string `env:"DB_USER"`
+	}
+
+	doc := NewDoc()
+	NewEmpty().parseDocGroup(doc, new(TestConfig))
+
+	assert.Equal(t, "DB_PASSWORD_FILE", doc.Fields[0].FileKey)
+	assert.Equal(t, "", doc.Fields[1].FileKey)
+
+	doc = NewDoc()
+	NewEmpty().UseFileIndirection().parseDocGroup(doc, new(TestConfig))
+
+	assert.Equal(t, "DB_USER_FILE", doc.Fields[1].FileKey)
+}
+
+func Test_EmptyValues(t *testing.T) {
+	type TestConfig struct {
+		Unset    string  `env:"EMPTY_UNSET" default:"fallback"`
+		Accepted string  `env:"EMPTY_ACCEPTED,empty=accept" default:"fallback"`
+		Number   int     `env:"EMPTY_NUMBER,empty=accept" default:"42"`
+		Pointer  *string `env:"EMPTY_POINTER,empty=accept,omitempty"`
+		Missing  string  `env:"EMPTY_MISSING,empty=accept" default:"fallback"`
+	}
+
+	for _, k := range []string{"EMPTY_UNSET", "EMPTY_ACCEPTED", "EMPTY_NUMBER", "EMPTY_POINTER"} {
+		_ = os.Setenv(k, "")
+	}
+	_ = os.Unsetenv("EMPTY_MISSING")
+	defer func() {
+		for _, k := range []string{"EMPTY_UNSET", "EMPTY_ACCEPTED", "EMPTY_NUMBER", "EMPTY_POINTER"} {
+			_ = os.Unsetenv(k)
+		}
+	}()
+
+	t.Run("accept", func(t *testing.T) {
+		cfg := new(TestConfig)
+		report, err := NewDefault().Silent().UnmarshalWithReport(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "fallback", cfg.Unset)
+		assert.Equal(t, "", cfg.Accepted)
+		assert.Equal(t, 0, cfg.Number)
+		assert.NotNil(t, cfg.Pointer)
+		assert.Equal(t, "", *cfg.Pointer)
+		assert.Equal(t, "fallback", cfg.Missing)
+
+		accepted, ok := report.Field("Accepted")
+		assert.True(t, ok)
+		assert.Equal(t, "env", accepted.Provider)
+		assert.False(t, accepted.DefaultUsed)
+	})
+
+	t.Run("empty value of a higher-priority provider wins", func(t *testing.T) {
+		cfg := new(TestConfig)
+		err := NewDefault().Silent().AddValueProviders(getterProvider{"EMPTY_ACCEPTED": "lower"}).Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "", cfg.Accepted)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		type RejectConfig struct {
+			Rejected string `env:"EMPTY_UNSET,empty=reject" default:"fallback"`
+		}
+
+		err := NewDefault().Silent().Unmarshal(new(RejectConfig))
+
+		var emptyErr *EmptyValueError
+		assert.True(t, errors.As(err, &emptyErr))
+		assert.Equal(t, "env", emptyErr.Provider)
+		assert.EqualError(t, err, "EMPTY_UNSET is set to an empty value")
+	})
+
+	t.Run("validated", func(t *testing.T) {
+		type ValidatedConfig struct {
+			Name string `env:"EMPTY_UNSET,empty=accept" validate:"min=1"`
+		}
+
+		err := NewDefault().Silent().Unmarshal(new(ValidatedConfig))
+
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		type InvalidConfig struct {
+			Name string `env:"EMPTY_UNSET,empty=ignore"`
+		}
+
+		err := NewDefault().Silent().Unmarshal(new(InvalidConfig))
+
+		var tagErr *InvalidTagError
+		assert.True(t, errors.As(err, &tagErr))
+		assert.EqualError(t, err, `invalid tags on Name: invalid empty option "ignore": must be unset, accept or reject`)
+	})
+}
+
+func Test_KeyAliases(t *testing.T) {
+	type RedisConfig struct {
+		Password string `env:"PASSWORD|PASS|PWD,secret"`
+	}
+
+	type TestConfig struct {
+		Redis RedisConfig `envPrefix:"ALIAS_REDIS_"`
+		Host  string      `env:"ALIAS_HOST|ALIAS_OLD_HOST" default:"localhost"`
+	}
+
+	keys := []string{"ALIAS_REDIS_PASSWORD", "ALIAS_REDIS_PASS", "ALIAS_REDIS_PWD", "ALIAS_HOST", "ALIAS_OLD_HOST"}
+	reset := func() {
+		for _, k := range keys {
+			_ = os.Unsetenv(k)
+		}
+	}
+	reset()
+	defer reset()
+
+	t.Run("deprecated alias is used and warned about once", func(t *testing.T) {
+		defer reset()
+		_ = os.Setenv("ALIAS_REDIS_PWD", "hunter2")
+
+		logger := new(recordingLogger)
+		cfgManager := NewDefault().UseLogger(logger)
+
+		cfg := new(TestConfig)
+		report, err := cfgManager.UnmarshalWithReport(cfg)
+		assert.NoError(t, err)
+		assert.NoError(t, cfgManager.Unmarshal(new(TestConfig)))
+
+		assert.Equal(t, "hunter2", cfg.Redis.Password)
+		assert.Equal(t, "localhost", cfg.Host)
+
+		password, ok := report.Field("Redis.Password")
+		assert.True(t, ok)
+		assert.Equal(t, "ALIAS_REDIS_PWD", password.Key)
+
+		var warnings []logEntry
+		for _, entry := range logger.entries {
+			if entry.msg == "deprecated key used, rename it" {
+				warnings = append(warnings, entry)
+			}
+		}
+		assert.Equal(t, []logEntry{
+			{"WARN", "deprecated key used, rename it", []interface{}{"key", "ALIAS_REDIS_PASSWORD", "path", "Redis.Password", "source", "env", "deprecated_key", "ALIAS_REDIS_PWD"}},
+		}, warnings)
+	})
+
+	t.Run("key takes priority over agreeing aliases", func(t *testing.T) {
+		defer reset()
+		_ = os.Setenv("ALIAS_REDIS_PASSWORD", "hunter2")
+		_ = os.Setenv("ALIAS_REDIS_PWD", "hunter2")
+		_ = os.Setenv("ALIAS_OLD_HOST", "example.com")
+
+		logger := new(recordingLogger)
+		cfg := new(TestConfig)
+		err := NewDefault().UseLogger(logger).Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", cfg.Redis.Password)
+		assert.Equal(t, "example.com", cfg.Host)
+		assert.Len(t, logger.entries, 3)
+	})
+
+	t.Run("disagreeing aliases", func(t *testing.T) {
+		defer reset()
+		_ = os.Setenv("ALIAS_REDIS_PASS", "hunter2")
+		_ = os.Setenv("ALIAS_REDIS_PWD", "hunter3")
+
+		err := NewDefault().Silent().Unmarshal(new(TestConfig))
+
+		var conflictErr *AliasConflictError
+		assert.True(t, errors.As(err, &conflictErr))
+		assert.Equal(t, "Redis.Password", conflictErr.Path)
+		assert.Equal(t, "ALIAS_REDIS_PASS", conflictErr.Key)
+		assert.Equal(t, "ALIAS_REDIS_PWD", conflictErr.Alias)
+		assert.NotContains(t, err.Error(), "hunter")
+		assert.Contains(t, err.Error(), "ALIAS_REDIS_PASS and ALIAS_REDIS_PWD are set to different values")
+	})
+}
+
+func Test_parseDocGroup_KeyAliases(t *testing.T) {
+	type TestConfig struct {
+		Password string `env:"REDIS_PASSWORD|REDIS_PASS"`
+		Host     string `env:"REDIS_HOST"`
+	}
+
+	doc := NewDoc()
+	NewEmpty().parseDocGroup(doc, new(TestConfig))
+
+	assert.Equal(t, "REDIS_PASSWORD", doc.Fields[0].Key)
+	assert.Equal(t, []string{"REDIS_PASS"}, doc.Fields[0].DeprecatedKeys)
+	assert.Nil(t, doc.Fields[1].DeprecatedKeys)
+}
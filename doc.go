@@ -6,12 +6,25 @@ type DocField struct {
 	DefaultValue string
 	ExampleValue string
 	OmitEmpty    bool
+	// DeprecatedKeys are the aliases of Key kept for compatibility
+	DeprecatedKeys []string
+	// FileKey is the key naming a file the value may be read from instead, e.g. DB_PASSWORD_FILE
+	FileKey string
+	// AllowedValues, Min and Max come from the oneof, min and max validation rules.
+	// Min and Max are only set for numbers and durations, as they bound the length of other types.
+	AllowedValues []string
+	Min           string
+	Max           string
+	// Secret marks a sensitive field. Its DefaultValue and ExampleValue are always empty.
+	Secret bool
 }
 
 type DocTree struct {
 	Title  string
 	Fields []*DocField
 	Groups []*DocTree
+	// Indexed marks a template of a slice element, with keys shown for index 0
+	Indexed bool
 }
 
 func NewDoc() *DocTree {
@@ -0,0 +1,268 @@
+package gocfg
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// DumpFormat is the output format of Dump
+type DumpFormat string
+
+const (
+	// DumpFormatText renders an aligned table of keys, values, types and sources
+	DumpFormatText DumpFormat = "text"
+	// DumpFormatJSON renders a JSON array of DumpField objects
+	DumpFormatJSON DumpFormat = "json"
+	// DumpFormatEnv renders KEY=value lines, each preceded by a comment with the type and source
+	DumpFormatEnv DumpFormat = "env"
+)
+
+// SecretMask turns the value of a secret field into its dumped representation
+type SecretMask func(value string) string
+
+// MaskRedacted replaces secret values with ****
+func MaskRedacted(string) string {
+	return redactedValue
+}
+
+// MaskHash replaces secret values with a short prefix of their SHA-256 hash,
+// so values can be compared between dumps without being revealed
+func MaskHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[:8]
+}
+
+// DumpField is the loaded value of a single field
+type DumpField struct {
+	// Path is the dotted Go field path, e.g. RedisConfig.RedisPort
+	Path string `json:"path"`
+	Key  string `json:"key"`
+	// Value is the current value of the field, masked for secret fields
+	Value string `json:"value"`
+	Type  string `json:"type"`
+	// Secret reports whether Value is masked
+	Secret bool `json:"secret,omitempty"`
+	// Source is where the value came from; it is empty when no report was given or the field was not loaded
+	Source
+	DefaultUsed bool `json:"default,omitempty"`
+}
+
+// UseSecretMask sets how values of secret fields are rendered by Dump. MaskRedacted is used by default.
+func (c *ConfigManager) UseSecretMask(mask SecretMask) *ConfigManager {
+	c.checkMutable()
+	c.secretMask = mask
+	return c
+}
+
+// DumpFields returns the current values of all fields of cfg, a pointer to a loaded struct.
+// The sources of values are taken from report, which may be nil.
+// Values of secret fields are masked, and prefix maps are listed entry by entry.
+func (c *ConfigManager) DumpFields(cfg interface{}, report *Report) []*DumpField {
+	var fields []*DumpField
+	c.dumpFields(&fields, reflect.ValueOf(cfg).Elem(), structScope{}, report)
+	return fields
+}
+
+// Dump writes the current values of all fields of cfg to w in the given format. See DumpFields.
+func (c *ConfigManager) Dump(w io.Writer, format DumpFormat, cfg interface{}, report *Report) error {
+	fields := c.DumpFields(cfg, report)
+
+	switch format {
+	case DumpFormatText:
+		return writeTextDump(w, fields)
+	case DumpFormatJSON:
+		return writeJSONDump(w, fields)
+	case DumpFormatEnv:
+		return writeEnvDump(w, fields)
+	default:
+		return fmt.Errorf("unknown dump format %q", format)
+	}
+}
+
+// dumpFields appends the fields of val found in the given scope to fields
+func (c *ConfigManager) dumpFields(fields *[]*DumpField, val reflect.Value, scope structScope, report *Report) {
+	for _, plan := range c.typePlan(val.Type()).fields {
+		var (
+			field = val.Field(plan.index)
+			spec  = c.specInScope(plan, scope)
+		)
+
+		// Embedded structs of unexported types cannot be read as a whole, but their exported fields can
+		if !field.CanInterface() && plan.kind != structField || spec.key == "" && plan.kind != structField && plan.kind != structPtrField {
+			continue
+		}
+
+		switch {
+		case plan.kind == structField:
+			c.dumpFields(fields, field, spec.group, report)
+		case plan.kind == structPtrField:
+			if !field.IsNil() {
+				c.dumpFields(fields, field.Elem(), spec.group, report)
+			}
+		case plan.kind == structSliceField:
+			for j := 0; j < field.Len(); j++ {
+				c.dumpFields(fields, field.Index(j), spec.elementScope(j), report)
+			}
+		case plan.kind == prefixMapField && field.Kind() == reflect.Map:
+			keys := field.MapKeys()
+			sort.Slice(keys, func(a, b int) bool {
+				return formatValue(keys[a], spec) < formatValue(keys[b], spec)
+			})
+			for _, k := range keys {
+				name := formatValue(k, spec)
+				*fields = append(*fields, c.newDumpField(field.MapIndex(k), spec, spec.path+"["+name+"]", spec.key+name, report))
+			}
+		default:
+			*fields = append(*fields, c.newDumpField(field, spec, spec.path, spec.key, report))
+		}
+	}
+}
+
+func (c *ConfigManager) newDumpField(v reflect.Value, spec fieldSpec, path, key string, report *Report) *DumpField {
+	dumpField := &DumpField{
+		Path:   path,
+		Key:    key,
+		Value:  formatValue(v, spec),
+		Type:   v.Type().String(),
+		Secret: spec.secret,
+	}
+
+	if spec.secret && dumpField.Value != "" {
+		mask := c.secretMask
+		if mask == nil {
+			mask = MaskRedacted
+		}
+		dumpField.Value = mask(dumpField.Value)
+	}
+
+	if report != nil {
+		if fieldSource, ok := report.Field(path); ok {
+			dumpField.Source = fieldSource.Source
+			dumpField.DefaultUsed = fieldSource.DefaultUsed
+		} else if fieldSource, ok := report.Key(key); ok && spec.prefix {
+			// Entries of prefix maps are reported under their key, which keeps its original form
+			dumpField.Source = fieldSource.Source
+		}
+	}
+
+	return dumpField
+}
+
+// formatValue renders a field value the way it would be written in a value provider.
+// Nil pointers are rendered as an empty string.
+func formatValue(v reflect.Value, spec fieldSpec) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			return marshalText(m)
+		}
+		v = v.Elem()
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return marshalText(m)
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			return string(v.Bytes())
+		}
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = formatValue(v.Index(i), spec)
+		}
+		return strings.Join(parts, spec.separator)
+	case reflect.Map:
+		parts := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			parts = append(parts, formatValue(k, spec)+spec.kvSeparator+formatValue(v.MapIndex(k), spec))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, spec.separator)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func marshalText(m encoding.TextMarshaler) string {
+	text, err := m.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(text)
+}
+
+// formatSource describes a source as provider (file:line), or - when it is unknown
+func formatSource(s Source) string {
+	switch {
+	case s.Provider == "":
+		return "-"
+	case s.File == "":
+		return s.Provider
+	default:
+		return s.Provider + " (" + s.File + ":" + strconv.Itoa(s.Line) + ")"
+	}
+}
+
+func writeTextDump(w io.Writer, fields []*DumpField) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "KEY\tVALUE\tTYPE\tSOURCE"); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+
+	for _, f := range fields {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Key, strconv.Quote(f.Value), f.Type, formatSource(f.Source)); err != nil {
+			return fmt.Errorf("failed to write: %w", err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+func writeJSONDump(w io.Writer, fields []*DumpField) error {
+	if fields == nil {
+		fields = []*DumpField{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(fields); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+func writeEnvDump(w io.Writer, fields []*DumpField) error {
+	for _, f := range fields {
+		if _, err := fmt.Fprintf(w, "# %s %s, source: %s\n%s=%s\n", f.Path, f.Type, formatSource(f.Source), f.Key, quoteEnvValue(f.Value)); err != nil {
+			return fmt.Errorf("failed to write: %w", err)
+		}
+	}
+	return nil
+}
+
+// quoteEnvValue double-quotes values that would not be read back verbatim from an env file
+func quoteEnvValue(value string) string {
+	if strings.ContainsAny(value, " \t\r\n#\"'\\$`") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,136 @@
+package gocfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dumpRedisConfig struct {
+	Host     string `env:"DUMP_REDIS_HOST"`
+	Password string `env:"DUMP_REDIS_PASSWORD,secret"`
+}
+
+type dumpConfig struct {
+	Name     string          `env:"DUMP_NAME"`
+	Timeout  time.Duration   `env:"DUMP_TIMEOUT" default:"5s"`
+	Tags     []string        `env:"DUMP_TAGS,sep=;"`
+	Token    string          `env:"DUMP_TOKEN,sensitive"`
+	Optional *int            `env:"DUMP_OPTIONAL,omitempty"`
+	Flags    map[string]bool `env:"DUMP_FLAGS_,prefix,omitempty"`
+	Redis    dumpRedisConfig
+	Skipped  string            `env:"-"`
+	Labels   map[string]string `env:"DUMP_LABELS,omitempty"`
+}
+
+func setDumpEnv() func() {
+	env := map[string]string{
+		"DUMP_NAME":           "api server",
+		"DUMP_TAGS":           "a;b",
+		"DUMP_TOKEN":          "hunter2",
+		"DUMP_FLAGS_SEARCH":   "true",
+		"DUMP_REDIS_HOST":     "localhost",
+		"DUMP_REDIS_PASSWORD": "secret",
+	}
+	for k, v := range env {
+		_ = os.Setenv(k, v)
+	}
+	_ = os.Unsetenv("DUMP_TIMEOUT")
+
+	return func() {
+		for k := range env {
+			_ = os.Unsetenv(k)
+		}
+	}
+}
+
+func Test_DumpFields(t *testing.T) {
+	defer setDumpEnv()()
+
+	cfgManager := NewDefault().Silent()
+	cfg := new(dumpConfig)
+	report, err := cfgManager.UnmarshalWithReport(cfg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*DumpField{
+		{Path: "Name", Key: "DUMP_NAME", Value: "api server", Type: "string", Source: Source{Provider: "env"}},
+		{Path: "Timeout", Key: "DUMP_TIMEOUT", Value: "5s", Type: "time.Duration", Source: Source{Provider: "default"}, DefaultUsed: true},
+		{Path: "Tags", Key: "DUMP_TAGS", Value: "a;b", Type: "[]string", Source: Source{Provider: "env"}},
+		{Path: "Token", Key: "DUMP_TOKEN", Value: "****", Type: "string", Secret: true, Source: Source{Provider: "env"}},
+		{Path: "Optional", Key: "DUMP_OPTIONAL", Value: "", Type: "*int"},
+		{Path: "Flags[SEARCH]", Key: "DUMP_FLAGS_SEARCH", Value: "true", Type: "bool", Source: Source{Provider: "env"}},
+		{Path: "Redis.Host", Key: "DUMP_REDIS_HOST", Value: "localhost", Type: "string", Source: Source{Provider: "env"}},
+		{Path: "Redis.Password", Key: "DUMP_REDIS_PASSWORD", Value: "****", Type: "string", Secret: true, Source: Source{Provider: "env"}},
+		{Path: "Labels", Key: "DUMP_LABELS", Value: "", Type: "map[string]string"},
+	}, cfgManager.DumpFields(cfg, report))
+}
+
+func Test_DumpFields_WithoutReport(t *testing.T) {
+	cfg := &dumpConfig{
+		Labels: map[string]string{"b": "2", "a": "1"},
+		Token:  "hunter2",
+	}
+
+	fields := NewDefault().UseSecretMask(MaskHash).DumpFields(cfg, nil)
+
+	assert.Equal(t, "a:1,b:2", fields[7].Value)
+	assert.Equal(t, Source{}, fields[7].Source)
+	assert.Equal(t, MaskHash("hunter2"), fields[3].Value)
+	assert.Regexp(t, `^sha256:[0-9a-f]{8}$`, fields[3].Value)
+	assert.NotEqual(t, MaskHash("hunter3"), fields[3].Value)
+}
+
+func Test_Dump(t *testing.T) {
+	defer setDumpEnv()()
+
+	cfgManager := NewDefault().Silent()
+	cfg := new(dumpConfig)
+	report, err := cfgManager.UnmarshalWithReport(cfg)
+	assert.NoError(t, err)
+
+	t.Run("text", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		assert.NoError(t, cfgManager.Dump(buf, DumpFormatText, cfg, report))
+
+		assert.Contains(t, buf.String(), "KEY                  VALUE         TYPE               SOURCE\n")
+		assert.Contains(t, buf.String(), "DUMP_NAME            \"api server\"  string             env\n")
+		assert.Contains(t, buf.String(), "DUMP_TIMEOUT         \"5s\"          time.Duration      default\n")
+		assert.Contains(t, buf.String(), "DUMP_OPTIONAL        \"\"            *int               -\n")
+		assert.NotContains(t, buf.String(), "hunter2")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		assert.NoError(t, cfgManager.Dump(buf, DumpFormatJSON, cfg, report))
+		assert.NotContains(t, buf.String(), "hunter2")
+
+		var fields []map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+		assert.Equal(t, map[string]interface{}{
+			"path":    "Timeout",
+			"key":     "DUMP_TIMEOUT",
+			"value":   "5s",
+			"type":    "time.Duration",
+			"source":  "default",
+			"default": true,
+		}, fields[1])
+		assert.Equal(t, true, fields[3]["secret"])
+	})
+
+	t.Run("env", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		assert.NoError(t, cfgManager.Dump(buf, DumpFormatEnv, cfg, report))
+
+		assert.Contains(t, buf.String(), "# Name string, source: env\nDUMP_NAME=\"api server\"\n")
+		assert.Contains(t, buf.String(), "# Token string, source: env\nDUMP_TOKEN=****\n")
+		assert.Contains(t, buf.String(), "# Flags[SEARCH] bool, source: env\nDUMP_FLAGS_SEARCH=true\n")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.EqualError(t, cfgManager.Dump(new(bytes.Buffer), "yaml", cfg, report), `unknown dump format "yaml"`)
+	})
+}
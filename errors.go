@@ -0,0 +1,269 @@
+package gocfg
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldError holds the details shared by all field-level Unmarshal errors
+type FieldError struct {
+	// Path is the dotted Go field path, e.g. RedisConfig.RedisPort
+	Path string
+	// Key is the key the value was looked up by
+	Key string
+	// Value is the raw value, redacted for secret fields
+	Value string
+	// Provider is the name of the provider that supplied the value, or "default" for default values
+	Provider string
+	// Err is the underlying cause, if any. For secret fields its message has the value redacted.
+	Err error
+}
+
+// Unwrap returns the underlying cause
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// MissingValueError is returned when a required field has neither a value nor a default
+type MissingValueError struct {
+	FieldError
+}
+
+func (e *MissingValueError) Error() string {
+	return fmt.Sprintf("%s cannot be empty", e.Key)
+}
+
+// EmptyValueError is returned when a field marked with `empty=reject` is explicitly set to an empty value
+type EmptyValueError struct {
+	FieldError
+}
+
+func (e *EmptyValueError) Error() string {
+	return fmt.Sprintf("%s is set to an empty value", e.Key)
+}
+
+// AliasConflictError is returned when a key and its deprecated alias, or two aliases, are set to different values.
+// Key is the key taking priority and Alias the conflicting one.
+type AliasConflictError struct {
+	FieldError
+	Alias string
+}
+
+func (e *AliasConflictError) Error() string {
+	return fmt.Sprintf("%s and %s are set to different values", e.Key, e.Alias)
+}
+
+// ParseError is returned when a value cannot be parsed into the field type
+type ParseError struct {
+	FieldError
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse %s: %v", e.Key, e.Err)
+}
+
+// ProviderError is returned when a value provider fails to look up a key.
+// It aborts loading, even when errors are collected.
+type ProviderError struct {
+	FieldError
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("failed to look up %s in %s: %v", e.Key, e.Provider, e.Err)
+}
+
+// ExpansionError is returned when references in a value cannot be expanded,
+// e.g. because of a reference cycle or an unset ${KEY:?message} reference
+type ExpansionError struct {
+	FieldError
+}
+
+func (e *ExpansionError) Error() string {
+	return fmt.Sprintf("failed to expand %s: %v", e.Key, e.Err)
+}
+
+// UnsupportedTypeError is returned when none of the parser providers supports the field type
+type UnsupportedTypeError struct {
+	FieldError
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("failed to get parser for %s: unsupported", e.Key)
+}
+
+// ValidationError is returned when a parsed value does not satisfy a rule of the validate tag
+type ValidationError struct {
+	FieldError
+	// Rule is the failed rule as written in the tag, e.g. min=1
+	Rule string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s does not satisfy %s: %v", e.Key, e.Rule, e.Err)
+}
+
+// InvalidTagError is returned when the tags of a field cannot be interpreted
+type InvalidTagError struct {
+	FieldError
+}
+
+func (e *InvalidTagError) Error() string {
+	return fmt.Sprintf("invalid tags on %s: %v", e.Path, e.Err)
+}
+
+// FileError is returned when the file named by a KEY_FILE key cannot be read.
+// Its Key is the KEY_FILE key and its Value the path.
+type FileError struct {
+	FieldError
+	File string
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("failed to read %s: %v", e.Key, e.Err)
+}
+
+// HookError is returned when a Validate or AfterLoad hook of a struct fails
+type HookError struct {
+	// Path is the dotted Go field path of the struct, empty for the root structure
+	Path string
+	// Hook is the name of the failed method
+	Hook string
+	Err  error
+}
+
+func (e *HookError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %v", e.Hook, e.Err)
+	}
+	return fmt.Sprintf("%s.%s: %v", e.Path, e.Hook, e.Err)
+}
+
+// Unwrap returns the error returned by the hook
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// ElementCountError is returned when the number of elements found for a slice of structs is out of the allowed range
+type ElementCountError struct {
+	FieldError
+	Count int
+	Min   int
+	// Max is -1 when the number of elements is unbounded
+	Max int
+}
+
+func (e *ElementCountError) Error() string {
+	if e.Max < 0 {
+		return fmt.Sprintf("%s must have at least %d elements, got %d", e.Key, e.Min, e.Count)
+	}
+	return fmt.Sprintf("%s must have between %d and %d elements, got %d", e.Key, e.Min, e.Max, e.Count)
+}
+
+// MultiError is returned by Unmarshal when error collection is enabled and one or more fields failed.
+// Every item is prefixed with the dotted Go field path and stays reachable through errors.Is and errors.As.
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "%d errors occurred:", len(e.Errors))
+	for _, err := range e.Errors {
+		_, _ = fmt.Fprintf(&b, "\n\t* %s", err)
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the collected errors.
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// Is reports whether any of the collected errors matches target.
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first collected error that matches target.
+func (e *MultiError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *MultiError) add(path string, err error) {
+	e.Errors = append(e.Errors, fmt.Errorf("%s: %w", path, err))
+}
+
+// unwrapPath returns a collected error without the path added by add.
+// Hook errors are collected as is, as their message holds the path already.
+func unwrapPath(err error) error {
+	if _, ok := err.(*HookError); ok {
+		return err
+	}
+	return errors.Unwrap(err)
+}
+
+// redactedError hides a secret value in the message of the underlying error.
+// The underlying error is not returned by Unwrap, since it may embed the value, but errors.Is still matches it.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+// Is reports whether the underlying error matches target
+func (e *redactedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+// redactError replaces every occurrence of value in the message of err, along with the parts of value
+// delimited by separators, so errors about single elements of a list do not leak them either.
+// Quoted forms are replaced as well, as parsers often report their input with %q.
+func redactError(err error, value string, separators ...string) error {
+	secrets := []string{value}
+	for _, sep := range separators {
+		for _, s := range secrets {
+			for _, part := range strings.Split(s, sep) {
+				secrets = append(secrets, part, strings.TrimSpace(part))
+			}
+		}
+	}
+
+	// Longer secrets first, so no part of a longer one is left behind
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+
+	msg := err.Error()
+	for _, s := range secrets {
+		if s == "" {
+			continue
+		}
+		msg = strings.ReplaceAll(msg, strconv.Quote(s), strconv.Quote(redactedValue))
+		msg = strings.ReplaceAll(msg, s, redactedValue)
+	}
+
+	return &redactedError{err: err, msg: msg}
+}
@@ -0,0 +1,57 @@
+package gocfg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MultiError(t *testing.T) {
+	errSentinel := errors.New("sentinel")
+
+	multiErr := new(MultiError)
+	multiErr.add("First", errSentinel)
+	multiErr.add("Second.Nested", &strconv.NumError{Func: "Atoi", Num: "x", Err: strconv.ErrSyntax})
+
+	assert.Equal(t, "2 errors occurred:\n\t* First: sentinel\n\t* Second.Nested: strconv.Atoi: parsing \"x\": invalid syntax", multiErr.Error())
+	assert.True(t, errors.Is(multiErr, errSentinel))
+	assert.True(t, errors.Is(multiErr, strconv.ErrSyntax))
+	assert.False(t, errors.Is(multiErr, strconv.ErrRange))
+
+	var numErr *strconv.NumError
+	assert.True(t, errors.As(multiErr, &numErr))
+	assert.Equal(t, "x", numErr.Num)
+	assert.Len(t, multiErr.Unwrap(), 2)
+}
+
+func Test_MultiError_Single(t *testing.T) {
+	multiErr := new(MultiError)
+	multiErr.add("Field", errors.New("failure"))
+
+	assert.Equal(t, "Field: failure", multiErr.Error())
+}
+
+func Test_FieldErrorMessages(t *testing.T) {
+	cause := errors.New("cause")
+
+	missingErr := &MissingValueError{FieldError{Path: "Field", Key: "KEY"}}
+	assert.Equal(t, "KEY cannot be empty", missingErr.Error())
+	assert.Nil(t, errors.Unwrap(missingErr))
+
+	parseErr := &ParseError{FieldError{Path: "Field", Key: "KEY", Value: "v", Err: cause}}
+	assert.Equal(t, "failed to parse KEY: cause", parseErr.Error())
+	assert.True(t, errors.Is(parseErr, cause))
+
+	unsupportedErr := &UnsupportedTypeError{FieldError: FieldError{Path: "Field", Key: "KEY"}}
+	assert.Equal(t, "failed to get parser for KEY: unsupported", unsupportedErr.Error())
+}
+
+func Test_redactError(t *testing.T) {
+	err := redactError(fmt.Errorf("entry 0: invalid value for key %q: %w", "db", errors.New(`parsing "p@ss word": bad`)), "db: p@ss word ", ",", ":")
+
+	assert.EqualError(t, err, `entry 0: invalid value for key "****": parsing "****": bad`)
+	assert.Nil(t, errors.Unwrap(err))
+}
@@ -0,0 +1,177 @@
+package gocfg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// expander resolves ${KEY} references in values and defaults through the value providers,
+// falling back to the default values of the fields loaded by these keys
+type expander struct {
+	ctx  context.Context
+	c    *ConfigManager
+	root reflect.Type
+	// defaults maps keys to the default values of their fields, built on first use
+	defaults map[string]string
+}
+
+func newExpander(ctx context.Context, c *ConfigManager, root reflect.Type) *expander {
+	return &expander{ctx: ctx, c: c, root: root}
+}
+
+// expand replaces references in s, which is the value of key. Supported forms are:
+//   - ${KEY} - the value of KEY, or an empty string when it is unset;
+//   - ${KEY:-fallback} - the value of KEY, or fallback when it is unset or empty;
+//   - ${KEY:?message} - the value of KEY, or an error with message when it is unset or empty;
+//   - $$ - a literal $.
+//
+// Referenced values are expanded as well; a key referencing itself, directly or not, is an error.
+// A failed lookup of a referenced key is returned as a *ProviderError for the field at path.
+func (e *expander) expand(s, path, key string) (string, error) {
+	return e.expandString(s, path, []string{key})
+}
+
+func (e *expander) expandString(s, path string, stack []string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := matchingBrace(s, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated reference at position %d", i)
+			}
+
+			value, err := e.resolve(s[i+2:end], path, stack)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(value)
+			i = end
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// matchingBrace returns the index of the brace closing a reference whose body starts at start, or -1
+func matchingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '$':
+			i++
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// resolve returns the value of a single reference body, e.g. KEY:-fallback
+func (e *expander) resolve(ref, path string, stack []string) (string, error) {
+	name, operator, operand := ref, "", ""
+	if i := strings.Index(ref, ":"); i >= 0 && i+1 < len(ref) && (ref[i+1] == '-' || ref[i+1] == '?') {
+		name, operator, operand = ref[:i], ref[i:i+2], ref[i+2:]
+	}
+
+	if name == "" || strings.ContainsAny(name, "${}: ") {
+		return "", fmt.Errorf("invalid reference ${%s}", ref)
+	}
+
+	for _, k := range stack {
+		if k == name {
+			return "", fmt.Errorf("reference cycle: %s -> %s", strings.Join(stack, " -> "), name)
+		}
+	}
+
+	raw, err := e.lookup(path, name)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := e.expandString(raw, path, append(stack[:len(stack):len(stack)], name))
+	if err != nil || value != "" {
+		return value, err
+	}
+
+	switch operator {
+	case ":-":
+		return e.expandString(operand, path, stack)
+	case ":?":
+		if operand == "" {
+			operand = "is not set"
+		}
+		return "", fmt.Errorf("%s %s", name, operand)
+	default:
+		return "", nil
+	}
+}
+
+// lookup returns the raw value of key from the value providers, or the default value of its field.
+// With ForceDefaults, keys of fields resolve to their default value only, as provided values are ignored for fields,
+// while other keys, e.g. HOME, are still looked up in the value providers.
+func (e *expander) lookup(path, key string) (string, error) {
+	if e.defaults == nil {
+		e.defaults = make(map[string]string)
+		e.c.collectDefaults(e.defaults, e.root, structScope{})
+	}
+	defaultValue, isField := e.defaults[key]
+
+	if !e.c.forceDefaults || !isField {
+		value, _, err := e.c.getValue(e.ctx, path, key)
+		if err != nil || value != "" {
+			return value, err
+		}
+	}
+
+	if !e.c.useDefaults {
+		return "", nil
+	}
+
+	return defaultValue, nil
+}
+
+// collectDefaults maps the keys of the fields of struct type t found in the given scope to their default values,
+// which are empty for fields without one. Fields of slices of structs are not included, as their keys depend on the index.
+func (c *ConfigManager) collectDefaults(defaults map[string]string, t reflect.Type, scope structScope) {
+	scope = scope.enter(t)
+	for _, plan := range c.typePlan(t).fields {
+		spec := c.specInScope(plan, scope)
+
+		switch {
+		case plan.kind == structField:
+			c.collectDefaults(defaults, plan.typ, spec.group)
+		case plan.kind == structPtrField:
+			if !scope.encloses(plan.typ.Elem()) {
+				c.collectDefaults(defaults, plan.typ.Elem(), spec.group)
+			}
+		case spec.key != "":
+			if defaults[spec.key] == "" {
+				defaults[spec.key] = spec.defaultValue
+			}
+		}
+	}
+}
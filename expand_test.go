@@ -0,0 +1,172 @@
+package gocfg
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Expansion(t *testing.T) {
+	type DBConfig struct {
+		User string `env:"EXPAND_DB_USER"`
+		Host string `env:"EXPAND_DB_HOST" default:"localhost"`
+		Port int    `env:"EXPAND_DB_PORT" default:"${EXPAND_DEFAULT_PORT:-5432}"`
+	}
+
+	type TestConfig struct {
+		DB       DBConfig
+		URL      string `env:"EXPAND_DATABASE_URL,expand"`
+		CacheDir string `env:"EXPAND_CACHE_DIR,expand" default:"${EXPAND_HOME}/.cache/app"`
+		Price    string `env:"EXPAND_PRICE,expand"`
+		Raw      string `env:"EXPAND_RAW"`
+	}
+
+	_ = os.Setenv("EXPAND_DB_USER", "admin")
+	_ = os.Unsetenv("EXPAND_DB_HOST")
+	_ = os.Setenv("EXPAND_DB_PORT", "6432")
+	_ = os.Setenv("EXPAND_DATABASE_URL", "postgres://${EXPAND_DB_USER}@${EXPAND_DB_HOST}:${EXPAND_DB_PORT}/${EXPAND_DB_NAME:-app}")
+	_ = os.Unsetenv("EXPAND_CACHE_DIR")
+	_ = os.Setenv("EXPAND_HOME", "/home/app")
+	_ = os.Setenv("EXPAND_PRICE", "$$5 and $5")
+	_ = os.Setenv("EXPAND_RAW", "${EXPAND_HOME}")
+	defer func() {
+		for _, k := range []string{"EXPAND_DB_USER", "EXPAND_DB_PORT", "EXPAND_DATABASE_URL", "EXPAND_HOME", "EXPAND_PRICE", "EXPAND_RAW"} {
+			_ = os.Unsetenv(k)
+		}
+	}()
+
+	cfg := new(TestConfig)
+	err := NewDefault().Silent().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres://admin@localhost:6432/app", cfg.URL)
+	assert.Equal(t, "/home/app/.cache/app", cfg.CacheDir)
+	assert.Equal(t, "$5 and $5", cfg.Price)
+	assert.Equal(t, "${EXPAND_HOME}", cfg.Raw)
+
+	t.Run("global", func(t *testing.T) {
+		_ = os.Unsetenv("EXPAND_DB_PORT")
+		defer func() { _ = os.Setenv("EXPAND_DB_PORT", "6432") }()
+
+		cfg := new(TestConfig)
+		err := NewDefault().Silent().UseExpansion().Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5432, cfg.DB.Port)
+		assert.Equal(t, "postgres://admin@localhost:5432/app", cfg.URL)
+		assert.Equal(t, "/home/app", cfg.Raw)
+	})
+}
+
+func Test_Expansion_Errors(t *testing.T) {
+	type TestConfig struct {
+		Value string `env:"EXPAND_ERR_VALUE,expand,secret"`
+		A     string `env:"EXPAND_ERR_A,omitempty"`
+		B     string `env:"EXPAND_ERR_B,omitempty"`
+	}
+
+	defer func() {
+		_ = os.Unsetenv("EXPAND_ERR_VALUE")
+		_ = os.Unsetenv("EXPAND_ERR_A")
+		_ = os.Unsetenv("EXPAND_ERR_B")
+	}()
+
+	tests := []struct {
+		name  string
+		value string
+		a, b  string
+		err   string
+	}{
+		{
+			name:  "required reference",
+			value: "${EXPAND_ERR_MISSING:?must be set for the value}",
+			err:   "failed to expand EXPAND_ERR_VALUE: EXPAND_ERR_MISSING must be set for the value",
+		},
+		{
+			name:  "required reference without message",
+			value: "${EXPAND_ERR_MISSING:?}",
+			err:   "failed to expand EXPAND_ERR_VALUE: EXPAND_ERR_MISSING is not set",
+		},
+		{
+			name:  "cycle",
+			value: "${EXPAND_ERR_A}",
+			a:     "x${EXPAND_ERR_B}",
+			b:     "${EXPAND_ERR_VALUE}",
+			err:   "failed to expand EXPAND_ERR_VALUE: reference cycle: EXPAND_ERR_VALUE -> EXPAND_ERR_A -> EXPAND_ERR_B -> EXPAND_ERR_VALUE",
+		},
+		{
+			name:  "self reference",
+			value: "${EXPAND_ERR_VALUE:-x}",
+			err:   "failed to expand EXPAND_ERR_VALUE: reference cycle: EXPAND_ERR_VALUE -> EXPAND_ERR_VALUE",
+		},
+		{
+			name:  "unterminated",
+			value: "abc${EXPAND_ERR_A",
+			err:   "failed to expand EXPAND_ERR_VALUE: unterminated reference at position 3",
+		},
+		{
+			name:  "invalid",
+			value: "${}",
+			err:   "failed to expand EXPAND_ERR_VALUE: invalid reference ****",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Setenv("EXPAND_ERR_VALUE", tt.value)
+			_ = os.Setenv("EXPAND_ERR_A", tt.a)
+			_ = os.Setenv("EXPAND_ERR_B", tt.b)
+
+			err := NewDefault().Silent().Unmarshal(new(TestConfig))
+
+			var expansionErr *ExpansionError
+			assert.True(t, errors.As(err, &expansionErr))
+			assert.Equal(t, "****", expansionErr.Value)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func Test_Expansion_PrefixMap(t *testing.T) {
+	type TestConfig struct {
+		Hosts map[string]string `env:"EXPAND_HOSTS_,prefix,expand"`
+	}
+
+	_ = os.Setenv("EXPAND_HOSTS_PRIMARY", "${EXPAND_DOMAIN}")
+	_ = os.Setenv("EXPAND_DOMAIN", "example.com")
+	defer func() {
+		_ = os.Unsetenv("EXPAND_HOSTS_PRIMARY")
+		_ = os.Unsetenv("EXPAND_DOMAIN")
+	}()
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"PRIMARY": "example.com"}, cfg.Hosts)
+}
+
+func Test_Expansion_ForceDefaults(t *testing.T) {
+	type TestConfig struct {
+		Home     string `env:"EXPAND_FORCED_APP_HOME" default:"/srv"`
+		CacheDir string `env:"EXPAND_FORCED_CACHE_DIR,expand" default:"${EXPAND_FORCED_HOME}/.cache/app"`
+		DataDir  string `env:"EXPAND_FORCED_DATA_DIR,expand" default:"${EXPAND_FORCED_APP_HOME}/data"`
+	}
+
+	_ = os.Setenv("EXPAND_FORCED_HOME", "/home/app")
+	_ = os.Setenv("EXPAND_FORCED_APP_HOME", "/ignored")
+	defer func() {
+		_ = os.Unsetenv("EXPAND_FORCED_HOME")
+		_ = os.Unsetenv("EXPAND_FORCED_APP_HOME")
+	}()
+
+	cfg := new(TestConfig)
+	err := NewDefault().Silent().ForceDefaults().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/srv", cfg.Home)
+	assert.Equal(t, "/home/app/.cache/app", cfg.CacheDir)
+	assert.Equal(t, "/srv/data", cfg.DataDir)
+}
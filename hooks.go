@@ -0,0 +1,62 @@
+package gocfg
+
+import "reflect"
+
+// Defaulter is implemented by structs that set their own defaults. SetDefaults is called before the struct is loaded;
+// fields it sets are kept when neither a value nor a default tag is found for them.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// Validator is implemented by structs that check their fields, e.g. across several of them.
+// Validate is called once all fields of the struct, including nested structs, are filled.
+type Validator interface {
+	Validate() error
+}
+
+// AfterLoader is implemented by structs that derive computed fields. AfterLoad is called after Validate.
+type AfterLoader interface {
+	AfterLoad() error
+}
+
+// canCallHooks reports whether the methods of struct val can be called, which is not the case
+// for embedded structs of unexported types
+func canCallHooks(val reflect.Value) bool {
+	return val.CanAddr() && val.Addr().CanInterface()
+}
+
+// callSetDefaults calls SetDefaults if the addressable struct val implements Defaulter and reports whether it did
+func callSetDefaults(val reflect.Value) bool {
+	if !canCallHooks(val) {
+		return false
+	}
+
+	defaulter, ok := val.Addr().Interface().(Defaulter)
+	if ok {
+		defaulter.SetDefaults()
+	}
+	return ok
+}
+
+// callAfterFillHooks calls Validate and AfterLoad if the addressable struct val implements them
+func callAfterFillHooks(val reflect.Value, path string) error {
+	if !canCallHooks(val) {
+		return nil
+	}
+
+	ptr := val.Addr().Interface()
+
+	if validator, ok := ptr.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return &HookError{Path: path, Hook: "Validate", Err: err}
+		}
+	}
+
+	if afterLoader, ok := ptr.(AfterLoader); ok {
+		if err := afterLoader.AfterLoad(); err != nil {
+			return &HookError{Path: path, Hook: "AfterLoad", Err: err}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package gocfg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPoolConfig struct {
+	MinConns int    `env:"HOOKS_MIN_CONNS"`
+	MaxConns int    `env:"HOOKS_MAX_CONNS"`
+	Name     string `env:"HOOKS_POOL_NAME"`
+	Label    string `env:"-"`
+	calls    []string
+}
+
+func (c *testPoolConfig) SetDefaults() {
+	c.Name = "default-pool"
+	c.calls = append(c.calls, "SetDefaults")
+}
+
+func (c *testPoolConfig) Validate() error {
+	c.calls = append(c.calls, "Validate")
+	if c.MinConns > c.MaxConns {
+		return errors.New("MinConns must not exceed MaxConns")
+	}
+	return nil
+}
+
+func (c *testPoolConfig) AfterLoad() error {
+	c.calls = append(c.calls, "AfterLoad")
+	c.Label = fmt.Sprintf("%s[%d..%d]", c.Name, c.MinConns, c.MaxConns)
+	return nil
+}
+
+type testHooksConfig struct {
+	Pool testPoolConfig
+}
+
+func (c *testHooksConfig) Validate() error {
+	if c.Pool.Name == "forbidden" {
+		return errors.New("forbidden pool name")
+	}
+	return nil
+}
+
+func Test_Hooks(t *testing.T) {
+	_ = os.Setenv("HOOKS_MIN_CONNS", "1")
+	_ = os.Setenv("HOOKS_MAX_CONNS", "10")
+	_ = os.Unsetenv("HOOKS_POOL_NAME")
+
+	cfg := new(testHooksConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"SetDefaults", "Validate", "AfterLoad"}, cfg.Pool.calls)
+	assert.Equal(t, "default-pool", cfg.Pool.Name)
+	assert.Equal(t, "default-pool[1..10]", cfg.Pool.Label)
+}
+
+func Test_Hooks_ValueOverridesSetDefaults(t *testing.T) {
+	_ = os.Setenv("HOOKS_MIN_CONNS", "1")
+	_ = os.Setenv("HOOKS_MAX_CONNS", "10")
+	_ = os.Setenv("HOOKS_POOL_NAME", "primary")
+	defer func() { _ = os.Unsetenv("HOOKS_POOL_NAME") }()
+
+	cfg := new(testHooksConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", cfg.Pool.Name)
+}
+
+func Test_Hooks_ValidateError(t *testing.T) {
+	_ = os.Setenv("HOOKS_MIN_CONNS", "10")
+	_ = os.Setenv("HOOKS_MAX_CONNS", "1")
+
+	cfg := new(testHooksConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	var hookErr *HookError
+	assert.True(t, errors.As(err, &hookErr))
+	assert.Equal(t, "Pool", hookErr.Path)
+	assert.Equal(t, "Validate", hookErr.Hook)
+	assert.Contains(t, err.Error(), "Pool.Validate: MinConns must not exceed MaxConns")
+	assert.Equal(t, []string{"SetDefaults", "Validate"}, cfg.Pool.calls)
+}
+
+func Test_Hooks_RootValidateError(t *testing.T) {
+	_ = os.Setenv("HOOKS_MIN_CONNS", "1")
+	_ = os.Setenv("HOOKS_MAX_CONNS", "10")
+	_ = os.Setenv("HOOKS_POOL_NAME", "forbidden")
+	defer func() { _ = os.Unsetenv("HOOKS_POOL_NAME") }()
+
+	err := NewDefault().CollectErrors().Unmarshal(new(testHooksConfig))
+
+	var hookErr *HookError
+	assert.True(t, errors.As(err, &hookErr))
+	assert.Equal(t, "", hookErr.Path)
+	assert.Equal(t, "Validate: forbidden pool name", hookErr.Error())
+}
+
+func Test_Hooks_SkippedOnFieldErrors(t *testing.T) {
+	_ = os.Setenv("HOOKS_MIN_CONNS", "invalid")
+	_ = os.Setenv("HOOKS_MAX_CONNS", "1")
+
+	cfg := new(testHooksConfig)
+	err := NewDefault().CollectErrors().Unmarshal(cfg)
+
+	var multiErr *MultiError
+	assert.True(t, errors.As(err, &multiErr))
+	assert.Len(t, multiErr.Errors, 1)
+	assert.Equal(t, []string{"SetDefaults"}, cfg.Pool.calls)
+}
+
+type testAfterLoadErrorConfig struct {
+	Value string `env:"HOOKS_AFTER_LOAD_VALUE,omitempty"`
+}
+
+func (c *testAfterLoadErrorConfig) AfterLoad() error {
+	return errors.New("cannot derive")
+}
+
+func Test_Hooks_AfterLoadError(t *testing.T) {
+	errCause := errors.New("cannot derive")
+
+	err := NewDefault().Unmarshal(new(testAfterLoadErrorConfig))
+
+	var hookErr *HookError
+	assert.True(t, errors.As(err, &hookErr))
+	assert.Equal(t, "AfterLoad", hookErr.Hook)
+	assert.Equal(t, errCause.Error(), errors.Unwrap(hookErr).Error())
+}
+
+type testHooksUpstream struct {
+	Host string `env:"HOST"`
+}
+
+func (u *testHooksUpstream) Validate() error {
+	if u.Host == "localhost" {
+		return errors.New("localhost is not allowed")
+	}
+	return nil
+}
+
+func Test_Hooks_NestedValidateError(t *testing.T) {
+	t.Run("struct slice element", func(t *testing.T) {
+		type TestConfig struct {
+			Upstreams []testHooksUpstream `env:"HOOKS_UPSTREAMS"`
+		}
+
+		_ = os.Setenv("HOOKS_UPSTREAMS_0_HOST", "localhost")
+		defer func() { _ = os.Unsetenv("HOOKS_UPSTREAMS_0_HOST") }()
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var hookErr *HookError
+		assert.True(t, errors.As(err, &hookErr))
+		assert.Equal(t, "Upstreams[0]", hookErr.Path)
+		assert.Equal(t, "Upstreams[0].Validate: localhost is not allowed", err.Error())
+	})
+
+	t.Run("optional group", func(t *testing.T) {
+		type TestConfig struct {
+			Upstream *testHooksUpstream `envPrefix:"HOOKS_UPSTREAM_" env:",omitempty"`
+		}
+
+		_ = os.Setenv("HOOKS_UPSTREAM_HOST", "localhost")
+		defer func() { _ = os.Unsetenv("HOOKS_UPSTREAM_HOST") }()
+
+		err := NewDefault().Unmarshal(new(TestConfig))
+
+		var hookErr *HookError
+		assert.True(t, errors.As(err, &hookErr))
+		assert.Equal(t, "Upstream", hookErr.Path)
+		assert.Contains(t, err.Error(), "Upstream.Validate: localhost is not allowed")
+	})
+}
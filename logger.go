@@ -0,0 +1,61 @@
+package gocfg
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger receives messages about the loading process. Args are alternating key and value pairs,
+// so a *slog.Logger can be used as is.
+//
+// Attributes passed by ConfigManager are:
+//   - key: the key of the field
+//   - path: the dotted Go field path
+//   - source: the name of the provider that supplied the value, or "default"
+//   - default: the default value used, redacted for secret fields
+//   - file: the file the value was read from, for values read from a file named by KEY_FILE
+//   - deprecated_key: the deprecated alias of the key that supplied the value
+//   - unset_key: the key that could not be removed for a field marked with the unset option
+//   - error: the error of a failed reload of a watched configuration
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// stdLogger writes warnings and errors to the standard logger of the log package
+type stdLogger struct{}
+
+func (l stdLogger) Debug(string, ...interface{}) {}
+
+func (l stdLogger) Info(string, ...interface{}) {}
+
+func (l stdLogger) Warn(msg string, args ...interface{}) {
+	l.print("WARNING", msg, args)
+}
+
+func (l stdLogger) Error(msg string, args ...interface{}) {
+	l.print("ERROR", msg, args)
+}
+
+func (l stdLogger) print(level, msg string, args []interface{}) {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		_, _ = fmt.Fprintf(&b, " %v=%q", args[i], fmt.Sprint(args[i+1]))
+	}
+	log.Printf("%s: %s", level, b.String())
+}
+
+// nopLogger discards all messages
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+
+func (nopLogger) Info(string, ...interface{}) {}
+
+func (nopLogger) Warn(string, ...interface{}) {}
+
+func (nopLogger) Error(string, ...interface{}) {}
@@ -0,0 +1,124 @@
+package gocfg
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type logEntry struct {
+	level string
+	msg   string
+	args  []interface{}
+}
+
+type recordingLogger struct {
+	entries []logEntry
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) {
+	l.entries = append(l.entries, logEntry{"DEBUG", msg, args})
+}
+
+func (l *recordingLogger) Info(msg string, args ...interface{}) {
+	l.entries = append(l.entries, logEntry{"INFO", msg, args})
+}
+
+func (l *recordingLogger) Warn(msg string, args ...interface{}) {
+	l.entries = append(l.entries, logEntry{"WARN", msg, args})
+}
+
+func (l *recordingLogger) Error(msg string, args ...interface{}) {
+	l.entries = append(l.entries, logEntry{"ERROR", msg, args})
+}
+
+func Test_UseLogger(t *testing.T) {
+	type TestConfig struct {
+		Host     string `env:"LOGGER_HOST"`
+		Port     int    `env:"LOGGER_PORT" default:"6379"`
+		Password string `env:"LOGGER_PASSWORD,secret" default:"hunter2"`
+	}
+
+	_ = os.Setenv("LOGGER_HOST", "localhost")
+	_ = os.Unsetenv("LOGGER_PORT")
+	_ = os.Unsetenv("LOGGER_PASSWORD")
+
+	logger := new(recordingLogger)
+	err := NewDefault().UseLogger(logger).Unmarshal(new(TestConfig))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []logEntry{
+		{"DEBUG", "value loaded", []interface{}{"key", "LOGGER_HOST", "path", "Host", "source", "env"}},
+		{"WARN", "value not found, using default value", []interface{}{"key", "LOGGER_PORT", "path", "Port", "source", "default", "default", "6379"}},
+		{"WARN", "value not found, using default value", []interface{}{"key", "LOGGER_PASSWORD", "path", "Password", "source", "default", "default", "****"}},
+	}, logger.entries)
+}
+
+func Test_UseLogger_ForceDefaults(t *testing.T) {
+	type TestConfig struct {
+		Port int `env:"LOGGER_FORCED_PORT" default:"6379"`
+	}
+
+	logger := new(recordingLogger)
+	err := NewDefault().ForceDefaults().UseLogger(logger).Unmarshal(new(TestConfig))
+
+	assert.NoError(t, err)
+	assert.Len(t, logger.entries, 1)
+	assert.Equal(t, "DEBUG", logger.entries[0].level)
+	assert.Equal(t, "using forced default value", logger.entries[0].msg)
+}
+
+func Test_StdLogger(t *testing.T) {
+	type TestConfig struct {
+		Port     int    `env:"STD_LOGGER_PORT" default:"6379"`
+		Password string `env:"STD_LOGGER_PASSWORD,secret" default:"hunter2"`
+	}
+
+	_ = os.Unsetenv("STD_LOGGER_PORT")
+	_ = os.Unsetenv("STD_LOGGER_PASSWORD")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	err := NewDefault().Unmarshal(new(TestConfig))
+
+	assert.NoError(t, err)
+	assert.Equal(t, `WARNING: value not found, using default value key="STD_LOGGER_PORT" path="Port" source="default" default="6379"
+WARNING: value not found, using default value key="STD_LOGGER_PASSWORD" path="Password" source="default" default="****"
+`, buf.String())
+
+	buf.Reset()
+	stdLogger{}.Error("failed", "key", "K")
+	stdLogger{}.Info("ignored")
+	stdLogger{}.Debug("ignored")
+	assert.Equal(t, "ERROR: failed key=\"K\"\n", buf.String())
+}
+
+func Test_Silent(t *testing.T) {
+	type TestConfig struct {
+		Port int `env:"SILENT_PORT" default:"6379"`
+	}
+
+	_ = os.Unsetenv("SILENT_PORT")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	err := NewDefault().Silent().Unmarshal(new(TestConfig))
+
+	assert.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	nopLogger{}.Debug("")
+	nopLogger{}.Info("")
+	nopLogger{}.Error("")
+}
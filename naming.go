@@ -0,0 +1,81 @@
+package gocfg
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives a key from the names of the fields leading to a field, e.g. ["RedisConfig", "MaxIdleConns"]
+type NamingStrategy func(names []string) string
+
+var (
+	// ScreamingSnakeCase derives keys like REDIS_CONFIG_MAX_IDLE_CONNS
+	ScreamingSnakeCase NamingStrategy = func(names []string) string {
+		return strings.ToUpper(strings.Join(splitWords(names), "_"))
+	}
+
+	// DottedLowerCase derives keys like redis.config.max.idle.conns
+	DottedLowerCase NamingStrategy = func(names []string) string {
+		return strings.ToLower(strings.Join(splitWords(names), "."))
+	}
+
+	// KebabCase derives keys like redis-config-max-idle-conns
+	KebabCase NamingStrategy = func(names []string) string {
+		return strings.ToLower(strings.Join(splitWords(names), "-"))
+	}
+)
+
+// splitWords splits Go identifiers into words, keeping acronyms together: HTTPServerV2 becomes HTTP, Server, V2
+func splitWords(names []string) []string {
+	var words []string
+	for _, name := range names {
+		runes := []rune(name)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			var (
+				prev      = runes[i-1]
+				cur       = runes[i]
+				nextLower = i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			)
+
+			if cur == '_' {
+				if i > start {
+					words = append(words, string(runes[start:i]))
+				}
+				start = i + 1
+				continue
+			}
+
+			if unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower)) {
+				if i > start {
+					words = append(words, string(runes[start:i]))
+				}
+				start = i
+			}
+		}
+		if start < len(runes) {
+			words = append(words, string(runes[start:]))
+		}
+	}
+	return words
+}
+
+// upperCaseKey derives a key like REDISCONFIG_MAXCONNS from the names of the fields leading to a field, as done by
+// kelseyhightower/envconfig. When split is true, the name of the field itself is split into words: REDISCONFIG_MAX_CONNS.
+func upperCaseKey(names []string, split bool) string {
+	last := len(names) - 1
+
+	words := names[last:]
+	if split {
+		words = splitWords(words)
+	}
+
+	return strings.ToUpper(strings.Join(append(names[:last:last], words...), "_"))
+}
+
+// parseBoolTag reports whether a tag holds a true boolean, e.g. `split_words:"true"`. Invalid values are false.
+func parseBoolTag(value string) bool {
+	b, _ := strconv.ParseBool(value)
+	return b
+}
@@ -0,0 +1,107 @@
+package gocfg
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NamingStrategies(t *testing.T) {
+	names := []string{"RedisConfig", "MaxIdleConns"}
+
+	assert.Equal(t, "REDIS_CONFIG_MAX_IDLE_CONNS", ScreamingSnakeCase(names))
+	assert.Equal(t, "redis.config.max.idle.conns", DottedLowerCase(names))
+	assert.Equal(t, "redis-config-max-idle-conns", KebabCase(names))
+}
+
+func Test_splitWords(t *testing.T) {
+	testCases := map[string][]string{
+		"MaxIdleConns": {"Max", "Idle", "Conns"},
+		"HTTPServerV2": {"HTTP", "Server", "V2"},
+		"RedisDB":      {"Redis", "DB"},
+		"Port":         {"Port"},
+		"TLS":          {"TLS"},
+		"Snake_Case":   {"Snake", "Case"},
+		"ID2Name":      {"ID2", "Name"},
+	}
+
+	for name, expected := range testCases {
+		assert.Equal(t, expected, splitWords([]string{name}), name)
+	}
+}
+
+func Test_UseNamingStrategy(t *testing.T) {
+	type PostgresConfig struct {
+		Host string
+		Port int `default:"5432"`
+	}
+
+	type RedisConfig struct {
+		MaxIdleConns int
+		Host         string `env:"NAMING_REDIS_HOST"`
+	}
+
+	type TestConfig struct {
+		RedisConfig RedisConfig
+		Replica     PostgresConfig `envPrefix:"NAMING_REPLICA_"`
+		Skipped     string         `env:"-"`
+		unexported  string
+	}
+
+	_ = os.Setenv("REDIS_CONFIG_MAX_IDLE_CONNS", "10")
+	_ = os.Setenv("NAMING_REDIS_HOST", "localhost")
+	_ = os.Setenv("NAMING_REPLICA_HOST", "replica")
+	_ = os.Unsetenv("NAMING_REPLICA_PORT")
+
+	cfg := &TestConfig{Skipped: "untouched"}
+	err := NewDefault().UseNamingStrategy(ScreamingSnakeCase).Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, cfg.RedisConfig.MaxIdleConns)
+	assert.Equal(t, "localhost", cfg.RedisConfig.Host)
+	assert.Equal(t, "replica", cfg.Replica.Host)
+	assert.Equal(t, 5432, cfg.Replica.Port)
+	assert.Equal(t, "untouched", cfg.Skipped)
+	assert.Equal(t, "", cfg.unexported)
+}
+
+func Test_SkipKeyWithoutNamingStrategy(t *testing.T) {
+	type TestConfig struct {
+		Skipped string `env:"-"`
+		Nested  struct {
+			Field string `env:"SKIP_NESTED_FIELD"`
+		} `env:"-"`
+	}
+
+	_ = os.Unsetenv("SKIP_NESTED_FIELD")
+
+	err := NewDefault().Unmarshal(new(TestConfig))
+
+	assert.NoError(t, err)
+}
+
+func Test_parseDocGroup_NamingStrategy(t *testing.T) {
+	type RedisConfig struct {
+		MaxIdleConns int
+	}
+
+	type TestConfig struct {
+		RedisConfig RedisConfig
+		Skipped     string `env:"-"`
+	}
+
+	docGroup := NewDoc()
+	NewEmpty().UseNamingStrategy(KebabCase).parseDocGroup(docGroup, new(TestConfig))
+
+	assert.Len(t, docGroup.Fields, 0)
+	assert.Len(t, docGroup.Groups, 1)
+	assert.Equal(t, "redis-config-max-idle-conns", docGroup.Groups[0].Fields[0].Key)
+}
+
+func Test_upperCaseKey(t *testing.T) {
+	assert.Equal(t, "MAXCONNS", upperCaseKey([]string{"MaxConns"}, false))
+	assert.Equal(t, "MAX_CONNS", upperCaseKey([]string{"MaxConns"}, true))
+	assert.Equal(t, "REDISCONFIG_MAXCONNS", upperCaseKey([]string{"RedisConfig", "MaxConns"}, false))
+	assert.Equal(t, "REDISCONFIG_MAX_CONNS", upperCaseKey([]string{"RedisConfig", "MaxConns"}, true))
+}
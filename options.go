@@ -0,0 +1,162 @@
+package gocfg
+
+import (
+	"sync"
+
+	"github.com/Jagerente/gocfg/pkg/parsers"
+	"github.com/Jagerente/gocfg/pkg/values"
+)
+
+// Option configures a ConfigManager created by New or derived by With
+type Option func(c *ConfigManager)
+
+// New creates an immutable ConfigManager with default tags and the given options, starting without providers.
+// Its settings cannot be changed afterwards, so it can be shared by goroutines and tests running in parallel;
+// derive variants with With or Clone instead. The builder methods, such as AddValueProviders, panic on it.
+//
+// Example:
+//
+//	cfgManager := gocfg.New(
+//		gocfg.WithDefaultSetup(),
+//		gocfg.WithValueProviders(dotEnvProvider),
+//		gocfg.WithLogger(slog.Default()),
+//	)
+func New(opts ...Option) *ConfigManager {
+	return NewEmpty().With(opts...)
+}
+
+// With returns an immutable copy of c with the options applied on top of its settings. c is left untouched.
+func (c *ConfigManager) With(opts ...Option) *ConfigManager {
+	derived := c.Clone()
+	for _, opt := range opts {
+		opt(derived)
+	}
+	derived.immutable = true
+	return derived
+}
+
+// Clone returns a mutable copy of c, whose settings can be changed with the builder methods without touching c.
+// Value and parser providers themselves are shared by both managers.
+func (c *ConfigManager) Clone() *ConfigManager {
+	clone := *c
+	clone.parserProviders = append([]ParserProvider(nil), c.parserProviders...)
+	clone.valueProviders = append([]ContextValueProvider(nil), c.valueProviders...)
+	clone.warnedAliases = new(sync.Map)
+	clone.immutable = false
+	return &clone
+}
+
+// checkMutable panics when the settings of c must not be changed
+func (c *ConfigManager) checkMutable() {
+	if c.immutable {
+		panic("gocfg: ConfigManager created by New or With is immutable, derive a variant with With or Clone")
+	}
+}
+
+// WithDefaultSetup adds the default parser provider and the environment value provider and enables defaults,
+// as NewDefault does
+func WithDefaultSetup() Option {
+	return func(c *ConfigManager) {
+		c.AddParserProviders(parsers.NewDefaultParserProvider()).
+			AddValueProviders(values.NewEnvProvider()).
+			UseDefaults()
+	}
+}
+
+// WithParserProviders adds parser providers, as AddParserProviders does
+func WithParserProviders(providers ...ParserProvider) Option {
+	return func(c *ConfigManager) {
+		c.AddParserProviders(providers...)
+	}
+}
+
+// WithValueProviders adds value providers, as AddValueProviders does
+func WithValueProviders(providers ...ValueProvider) Option {
+	return func(c *ConfigManager) {
+		c.AddValueProviders(providers...)
+	}
+}
+
+// WithContextValueProviders adds value providers that can report failures, as AddContextValueProviders does
+func WithContextValueProviders(providers ...ContextValueProvider) Option {
+	return func(c *ConfigManager) {
+		c.AddContextValueProviders(providers...)
+	}
+}
+
+// WithDefaults enables default values, as UseDefaults does
+func WithDefaults() Option {
+	return func(c *ConfigManager) {
+		c.UseDefaults()
+	}
+}
+
+// WithForcedDefaults uses default values even when a value is provided, as ForceDefaults does
+func WithForcedDefaults() Option {
+	return func(c *ConfigManager) {
+		c.ForceDefaults()
+	}
+}
+
+// WithErrorCollection returns every field error at once, as CollectErrors does
+func WithErrorCollection() Option {
+	return func(c *ConfigManager) {
+		c.CollectErrors()
+	}
+}
+
+// WithNamingStrategy derives keys of fields without a key tag, as UseNamingStrategy does
+func WithNamingStrategy(strategy NamingStrategy) Option {
+	return func(c *ConfigManager) {
+		c.UseNamingStrategy(strategy)
+	}
+}
+
+// WithLogger sets the logger, as UseLogger does
+func WithLogger(logger Logger) Option {
+	return func(c *ConfigManager) {
+		c.UseLogger(logger)
+	}
+}
+
+// WithoutLogging discards all messages about the loading process, as Silent does
+func WithoutLogging() Option {
+	return func(c *ConfigManager) {
+		c.Silent()
+	}
+}
+
+// WithFileIndirection reads values from files named by KEY_FILE for every field, as UseFileIndirection does
+func WithFileIndirection() Option {
+	return func(c *ConfigManager) {
+		c.UseFileIndirection()
+	}
+}
+
+// WithExpansion expands references in the values of every field, as UseExpansion does
+func WithExpansion() Option {
+	return func(c *ConfigManager) {
+		c.UseExpansion()
+	}
+}
+
+// WithKeyTag sets the key tag, as UseCustomKeyTag does
+func WithKeyTag(tag string) Option {
+	return func(c *ConfigManager) {
+		c.UseCustomKeyTag(tag)
+	}
+}
+
+// WithTagNames replaces the names of all struct tags, as UseTagNames does
+func WithTagNames(names TagNames) Option {
+	return func(c *ConfigManager) {
+		c.UseTagNames(names)
+	}
+}
+
+// WithSecretMask sets the mask of secret values in dumps, as UseSecretMask does
+func WithSecretMask(mask SecretMask) Option {
+	return func(c *ConfigManager) {
+		c.UseSecretMask(mask)
+	}
+}
@@ -0,0 +1,113 @@
+package gocfg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jagerente/gocfg/pkg/values"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	type TestConfig struct {
+		Host string `cfg:"OPTIONS_HOST" default:"localhost"`
+		Port int    `cfg:"OPTIONS_PORT"`
+	}
+
+	_ = os.Unsetenv("OPTIONS_HOST")
+	_ = os.Setenv("OPTIONS_PORT", "8080")
+	defer func() { _ = os.Unsetenv("OPTIONS_PORT") }()
+
+	logger := new(recordingLogger)
+	cfgManager := New(
+		WithDefaultSetup(),
+		WithKeyTag("cfg"),
+		WithLogger(logger),
+	)
+
+	cfg := new(TestConfig)
+	assert.NoError(t, cfgManager.Unmarshal(cfg))
+	assert.Equal(t, &TestConfig{Host: "localhost", Port: 8080}, cfg)
+	assert.Len(t, logger.entries, 2)
+
+	assert.PanicsWithValue(t, "gocfg: ConfigManager created by New or With is immutable, derive a variant with With or Clone", func() {
+		cfgManager.AddValueProviders(values.NewEnvProvider())
+	})
+	assert.Panics(t, func() { cfgManager.Silent() })
+	assert.Panics(t, func() { cfgManager.UseSecretMask(MaskHash) })
+}
+
+func Test_ConfigManager_With(t *testing.T) {
+	type TestConfig struct {
+		Host string `env:"OPTIONS_WITH_HOST" default:"localhost"`
+	}
+
+	_ = os.Unsetenv("OPTIONS_WITH_HOST")
+
+	dotEnvPath := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, os.WriteFile(dotEnvPath, []byte("OPTIONS_WITH_HOST=dotenv\n"), 0o600))
+
+	dotEnvProvider, err := values.NewDotEnvProvider(dotEnvPath)
+	assert.NoError(t, err)
+
+	base := New(WithDefaultSetup(), WithoutLogging())
+
+	t.Run("derived", func(t *testing.T) {
+		t.Parallel()
+
+		derived := base.With(WithValueProviders(dotEnvProvider))
+
+		cfg := new(TestConfig)
+		assert.NoError(t, derived.Unmarshal(cfg))
+		assert.Equal(t, "dotenv", cfg.Host)
+		assert.Panics(t, func() { derived.UseDefaults() })
+	})
+
+	t.Run("original", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := new(TestConfig)
+		assert.NoError(t, base.Unmarshal(cfg))
+		assert.Equal(t, "localhost", cfg.Host)
+	})
+
+	t.Run("mutable original is untouched", func(t *testing.T) {
+		original := NewDefault().Silent()
+		derived := original.With(WithValueProviders(dotEnvProvider), WithErrorCollection())
+
+		assert.Len(t, original.valueProviders, 1)
+		assert.Len(t, derived.valueProviders, 2)
+		assert.False(t, original.collectErrors)
+		assert.True(t, derived.collectErrors)
+
+		original.UseDefaults()
+	})
+}
+
+func Test_ConfigManager_Clone(t *testing.T) {
+	type TestConfig struct {
+		Host string `env:"OPTIONS_CLONE_HOST"`
+	}
+
+	_ = os.Setenv("OPTIONS_CLONE_HOST", "env")
+	defer func() { _ = os.Unsetenv("OPTIONS_CLONE_HOST") }()
+
+	base := New(WithDefaultSetup(), WithoutLogging())
+	assert.NoError(t, base.Unmarshal(new(TestConfig)))
+
+	clone := base.Clone().
+		UseCustomKeyTag("cfg").
+		UseNamingStrategy(ScreamingSnakeCase)
+
+	assert.NotSame(t, base.plans, clone.plans)
+	assert.Equal(t, "env", base.structKeyTag)
+	assert.Nil(t, base.namingStrategy)
+
+	cfg := new(TestConfig)
+	assert.Error(t, clone.Unmarshal(cfg))
+
+	cfg = new(TestConfig)
+	assert.NoError(t, base.Unmarshal(cfg))
+	assert.Equal(t, "env", cfg.Host)
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/Jagerente/gocfg"
 )
 
+// secretPlaceholder is written instead of the value of secret fields
+const secretPlaceholder = "<secret>"
+
 type EnvDocGenerator struct {
 	writer io.Writer
 }
@@ -61,6 +64,12 @@ func (g *EnvDocGenerator) writeGroup(group *gocfg.DocTree) error {
 		}
 	}
 
+	if group.Indexed {
+		if err := g.write("# List element: repeat this block for every element, replacing 0 with 1, 2, ...\n"); err != nil {
+			return err
+		}
+	}
+
 	for _, field := range group.Fields {
 		if err := g.writeField(field); err != nil {
 			return err
@@ -100,6 +109,24 @@ func (g *EnvDocGenerator) writeField(field *gocfg.DocField) error {
 		}
 	}
 
+	if len(field.DeprecatedKeys) > 0 {
+		if err := g.write(fmt.Sprintf("# Deprecated names: %s\n", strings.Join(field.DeprecatedKeys, ", "))); err != nil {
+			return err
+		}
+	}
+
+	if field.FileKey != "" {
+		if err := g.write(fmt.Sprintf("# Accepted as %s or as a path to a file in %s\n", field.Key, field.FileKey)); err != nil {
+			return err
+		}
+	}
+
+	if field.Secret {
+		if err := g.write("# Sensitive: do not commit the real value\n"); err != nil {
+			return err
+		}
+	}
+
 	if field.DefaultValue != "" {
 		if field.Description != "" {
 			if err := g.write("#\n"); err != nil {
@@ -111,11 +138,26 @@ func (g *EnvDocGenerator) writeField(field *gocfg.DocField) error {
 		}
 	}
 
+	if len(field.AllowedValues) > 0 {
+		if err := g.write(fmt.Sprintf("# Allowed values: %s\n", strings.Join(field.AllowedValues, ", "))); err != nil {
+			return err
+		}
+	}
+
+	if field.Min != "" || field.Max != "" {
+		if err := g.write(fmt.Sprintf("# Range: %s\n", g.buildRange(field.Min, field.Max))); err != nil {
+			return err
+		}
+	}
+
 	if field.Key != "" {
 		value := field.ExampleValue
 		if value == "" {
 			value = field.DefaultValue
 		}
+		if field.Secret {
+			value = secretPlaceholder
+		}
 		if err := g.write(fmt.Sprintf("%s=%s\n", field.Key, value)); err != nil {
 			return err
 		}
@@ -124,6 +166,17 @@ func (g *EnvDocGenerator) writeField(field *gocfg.DocField) error {
 	return nil
 }
 
+func (g *EnvDocGenerator) buildRange(min, max string) string {
+	switch {
+	case min == "":
+		return "<= " + max
+	case max == "":
+		return ">= " + min
+	default:
+		return min + "–" + max
+	}
+}
+
 func (g *EnvDocGenerator) writeBreakLine() error {
 	return g.write("\n")
 }
@@ -228,6 +228,46 @@ func TestEnvDocGenerator_writeGroup_NoError(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestEnvDocGenerator_writeGroup_Indexed(t *testing.T) {
+	doc := &gocfg.DocTree{
+		Title:   "Upstreams",
+		Indexed: true,
+		Fields:  []*gocfg.DocField{{Key: "UPSTREAMS_0_HOST", ExampleValue: "localhost"}},
+	}
+
+	var buf = new(bytes.Buffer)
+	envDocGen := NewEnvDocGenerator(buf)
+
+	err := envDocGen.writeGroup(doc)
+	assert.NoError(t, err)
+
+	expectedOutput := `
+#############################
+# Upstreams
+#############################
+# List element: repeat this block for every element, replacing 0 with 1, 2, ...
+
+UPSTREAMS_0_HOST=localhost
+`
+
+	assert.Equal(t, expectedOutput, buf.String())
+}
+
+func TestEnvDocGenerator_writeGroup_ErrorOnIndexed(t *testing.T) {
+	doc := &gocfg.DocTree{
+		Indexed: true,
+	}
+
+	failingWriter := &mockFailingWriter{
+		failAfter: 1,
+	}
+
+	envDocGen := &EnvDocGenerator{writer: failingWriter}
+
+	err := envDocGen.writeGroup(doc)
+	assert.Error(t, err)
+}
+
 func TestEnvDocGenerator_writeField_ErrorOnBreakLine(t *testing.T) {
 	failingWriter := &mockFailingWriter{
 		failAfter: 0,
@@ -346,6 +386,184 @@ func TestEnvDocGenerator_writeField_ErrorOnWriteDefaultComment_NoDescription(t *
 	assert.Error(t, err)
 }
 
+func TestEnvDocGenerator_GenerateDoc_WithValidation(t *testing.T) {
+	doc := &gocfg.DocTree{
+		Fields: []*gocfg.DocField{
+			{Key: "LOG_LEVEL", DefaultValue: "info", AllowedValues: []string{"debug", "info", "warn"}},
+			{Key: "SERVER_PORT", Min: "1", Max: "65535"},
+		},
+	}
+
+	var buf = new(bytes.Buffer)
+	envDocGen := NewEnvDocGenerator(buf)
+
+	err := envDocGen.GenerateDoc(doc)
+	assert.NoError(t, err)
+
+	expectedOutput := `# Auto-generated config
+
+# Default: ` + "`info`" + `
+# Allowed values: debug, info, warn
+LOG_LEVEL=info
+
+# Range: 1–65535
+SERVER_PORT=
+`
+
+	assert.Equal(t, expectedOutput, buf.String())
+}
+
+func TestEnvDocGenerator_GenerateDoc_Secret(t *testing.T) {
+	doc := &gocfg.DocTree{
+		Fields: []*gocfg.DocField{
+			{Key: "DB_PASSWORD", Description: "Database password", Secret: true},
+		},
+	}
+
+	var buf = new(bytes.Buffer)
+	envDocGen := NewEnvDocGenerator(buf)
+
+	err := envDocGen.GenerateDoc(doc)
+	assert.NoError(t, err)
+
+	expectedOutput := `# Auto-generated config
+
+# Description:
+#  Database password
+# Sensitive: do not commit the real value
+DB_PASSWORD=<secret>
+`
+
+	assert.Equal(t, expectedOutput, buf.String())
+}
+
+func TestEnvDocGenerator_GenerateDoc_FileKey(t *testing.T) {
+	doc := &gocfg.DocTree{
+		Fields: []*gocfg.DocField{
+			{Key: "DB_PASSWORD", FileKey: "DB_PASSWORD_FILE"},
+		},
+	}
+
+	var buf = new(bytes.Buffer)
+	envDocGen := NewEnvDocGenerator(buf)
+
+	err := envDocGen.GenerateDoc(doc)
+	assert.NoError(t, err)
+
+	expectedOutput := `# Auto-generated config
+
+# Accepted as DB_PASSWORD or as a path to a file in DB_PASSWORD_FILE
+DB_PASSWORD=
+`
+
+	assert.Equal(t, expectedOutput, buf.String())
+}
+
+func TestEnvDocGenerator_GenerateDoc_DeprecatedKeys(t *testing.T) {
+	doc := &gocfg.DocTree{
+		Fields: []*gocfg.DocField{
+			{Key: "REDIS_PASSWORD", DeprecatedKeys: []string{"REDIS_PASS", "REDIS_PWD"}},
+		},
+	}
+
+	var buf = new(bytes.Buffer)
+	envDocGen := NewEnvDocGenerator(buf)
+
+	err := envDocGen.GenerateDoc(doc)
+	assert.NoError(t, err)
+
+	expectedOutput := `# Auto-generated config
+
+# Deprecated names: REDIS_PASS, REDIS_PWD
+REDIS_PASSWORD=
+`
+
+	assert.Equal(t, expectedOutput, buf.String())
+}
+
+func TestEnvDocGenerator_writeField_ErrorOnWriteDeprecatedKeys(t *testing.T) {
+	field := &gocfg.DocField{
+		DeprecatedKeys: []string{"OLD_KEY"},
+	}
+
+	failingWriter := &mockFailingWriter{
+		failAfter: 1,
+	}
+
+	envDocGen := &EnvDocGenerator{writer: failingWriter}
+
+	err := envDocGen.writeField(field)
+	assert.Error(t, err)
+}
+
+func TestEnvDocGenerator_writeField_ErrorOnWriteFileKey(t *testing.T) {
+	field := &gocfg.DocField{
+		FileKey: "KEY_FILE",
+	}
+
+	failingWriter := &mockFailingWriter{
+		failAfter: 1,
+	}
+
+	envDocGen := &EnvDocGenerator{writer: failingWriter}
+
+	err := envDocGen.writeField(field)
+	assert.Error(t, err)
+}
+
+func TestEnvDocGenerator_writeField_ErrorOnWriteSecret(t *testing.T) {
+	field := &gocfg.DocField{
+		Secret: true,
+	}
+
+	failingWriter := &mockFailingWriter{
+		failAfter: 1,
+	}
+
+	envDocGen := &EnvDocGenerator{writer: failingWriter}
+
+	err := envDocGen.writeField(field)
+	assert.Error(t, err)
+}
+
+func TestEnvDocGenerator_writeField_ErrorOnWriteAllowedValues(t *testing.T) {
+	field := &gocfg.DocField{
+		AllowedValues: []string{"a"},
+	}
+
+	failingWriter := &mockFailingWriter{
+		failAfter: 1,
+	}
+
+	envDocGen := &EnvDocGenerator{writer: failingWriter}
+
+	err := envDocGen.writeField(field)
+	assert.Error(t, err)
+}
+
+func TestEnvDocGenerator_writeField_ErrorOnWriteRange(t *testing.T) {
+	field := &gocfg.DocField{
+		Min: "1",
+	}
+
+	failingWriter := &mockFailingWriter{
+		failAfter: 1,
+	}
+
+	envDocGen := &EnvDocGenerator{writer: failingWriter}
+
+	err := envDocGen.writeField(field)
+	assert.Error(t, err)
+}
+
+func TestEnvDocGenerator_buildRange(t *testing.T) {
+	envDocGen := &EnvDocGenerator{}
+
+	assert.Equal(t, "1–10", envDocGen.buildRange("1", "10"))
+	assert.Equal(t, ">= 1", envDocGen.buildRange("1", ""))
+	assert.Equal(t, "<= 10", envDocGen.buildRange("", "10"))
+}
+
 func TestEnvDocGenerator_buildHeader(t *testing.T) {
 	envDocGen := &EnvDocGenerator{}
 
@@ -40,7 +40,7 @@ var (
 			return v, nil
 		},
 		reflect.Int: func(v string) (interface{}, error) {
-			i, err := strconv.ParseInt(v, 10, 32)
+			i, err := strconv.ParseInt(v, 10, strconv.IntSize)
 			return int(i), err
 		},
 		reflect.Int16: func(v string) (interface{}, error) {
@@ -59,7 +59,7 @@ var (
 			return int8(i), err
 		},
 		reflect.Uint: func(v string) (interface{}, error) {
-			i, err := strconv.ParseUint(v, 10, 32)
+			i, err := strconv.ParseUint(v, 10, strconv.IntSize)
 			return uint(i), err
 		},
 		reflect.Uint16: func(v string) (interface{}, error) {
@@ -1,8 +1,14 @@
 package values
 
 import (
-	"github.com/joho/godotenv"
+	"bufio"
+	"bytes"
+	"context"
 	"os"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
 )
 
 const (
@@ -10,48 +16,134 @@ const (
 )
 
 type DotEnvProvider struct {
-	path   string
-	values map[string]string
+	paths     []string
+	mu        sync.RWMutex
+	values    map[string]string
+	locations map[string]location
+}
+
+// location is the place a variable is defined at
+type location struct {
+	path string
+	line int
 }
 
 func NewDotEnvProvider(paths ...string) (*DotEnvProvider, error) {
+	if len(paths) < 1 {
+		paths = []string{defaultEnvFile}
+	}
+
 	provider := &DotEnvProvider{
-		values: make(map[string]string),
+		paths: paths,
 	}
 
-	if len(paths) < 1 {
-		paths = []string{defaultEnvFile}
+	if err := provider.Reload(); err != nil {
+		return nil, err
 	}
 
-	for _, path := range paths {
-		file, err := os.Open(path)
+	return provider, nil
+}
+
+// Reload re-reads the env files. On error, the previously loaded values are kept.
+func (p *DotEnvProvider) Reload() error {
+	var (
+		values    = make(map[string]string)
+		locations = make(map[string]location)
+	)
+
+	for _, path := range p.paths {
+		content, err := os.ReadFile(path)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		values, err := godotenv.Parse(file)
+		parsed, err := godotenv.Parse(bytes.NewReader(content))
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		for key, value := range values {
-			if _, ok := provider.values[key]; ok {
+		lines := findKeyLines(content)
+		for key, value := range parsed {
+			if _, ok := values[key]; ok {
 				continue
 			}
 
-			provider.values[key] = value
+			values[key] = value
+			if line, ok := lines[key]; ok {
+				locations[key] = location{path: path, line: line}
+			}
 		}
-
-		_ = file.Close()
 	}
 
-	return provider, nil
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.values, p.locations = values, locations
+
+	return nil
 }
 
 func (p *DotEnvProvider) Get(key string) string {
-	if _, ok := p.values[key]; ok {
-		return p.values[key]
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.values[key]
+}
+
+// Lookup returns the value of the variable and whether it is defined in the env files, even with an empty value
+func (p *DotEnvProvider) Lookup(ctx context.Context, key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
 	}
 
-	return ""
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	value, ok := p.values[key]
+	return value, ok, nil
+}
+
+// Name returns the name of the provider
+func (p *DotEnvProvider) Name() string {
+	return "dotenv"
+}
+
+// Locate returns the file and the line the key is defined at
+func (p *DotEnvProvider) Locate(key string) (string, int, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	loc, ok := p.locations[key]
+	return loc.path, loc.line, ok
+}
+
+// Keys returns the names of all variables loaded from the env files
+func (p *DotEnvProvider) Keys() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	keys := make([]string, 0, len(p.values))
+	for key := range p.values {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// findKeyLines maps every key assigned in the env file content to the 1-based line of its last assignment,
+// which is the one godotenv keeps
+func findKeyLines(content []byte) map[string]int {
+	lines := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimPrefix(line, "export ")
+
+		i := strings.IndexAny(line, "=:")
+		if i <= 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lines[strings.TrimSpace(line[:i])] = n
+	}
+	return lines
 }
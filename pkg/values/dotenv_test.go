@@ -1,6 +1,7 @@
 package values
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
@@ -105,3 +106,89 @@ func Test_NonExistentFile(t *testing.T) {
 	_, err := NewDotEnvProvider("!@#$%^&*()_")
 	assert.Error(t, err)
 }
+
+func Test_DotEnvProviderKeys(t *testing.T) {
+	envFilePath, err := createTempEnvFile("VAR1=value1\nVAR2=value2")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(envFilePath) }()
+
+	provider, err := NewDotEnvProvider(envFilePath)
+	assert.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"VAR1", "VAR2"}, provider.Keys())
+}
+
+func Test_DotEnvProviderLocate(t *testing.T) {
+	envFilePath1, err := createTempEnvFile("# comment\nVAR1=value1\n\nexport VAR2=value2\nVAR2=overridden")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(envFilePath1) }()
+
+	envFilePath2, err := createTempEnvFile("VAR1=shadowed\nVAR3=value3")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(envFilePath2) }()
+
+	provider, err := NewDotEnvProvider(envFilePath1, envFilePath2)
+	assert.NoError(t, err)
+	assert.Equal(t, "dotenv", provider.Name())
+
+	file, line, ok := provider.Locate("VAR1")
+	assert.True(t, ok)
+	assert.Equal(t, envFilePath1, file)
+	assert.Equal(t, 2, line)
+
+	file, line, ok = provider.Locate("VAR2")
+	assert.True(t, ok)
+	assert.Equal(t, envFilePath1, file)
+	assert.Equal(t, 5, line)
+	assert.Equal(t, "overridden", provider.Get("VAR2"))
+
+	file, line, ok = provider.Locate("VAR3")
+	assert.True(t, ok)
+	assert.Equal(t, envFilePath2, file)
+	assert.Equal(t, 2, line)
+
+	_, _, ok = provider.Locate("NON_EXISTING_KEY")
+	assert.False(t, ok)
+}
+
+func Test_DotEnvProviderReload(t *testing.T) {
+	envFilePath, err := createTempEnvFile("VAR1=value1")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(envFilePath) }()
+
+	provider, err := NewDotEnvProvider(envFilePath)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(envFilePath, []byte("VAR1=changed\nVAR2=value2"), 0o600))
+	assert.NoError(t, provider.Reload())
+
+	assert.Equal(t, "changed", provider.Get("VAR1"))
+	assert.Equal(t, "value2", provider.Get("VAR2"))
+
+	assert.NoError(t, os.Remove(envFilePath))
+	assert.Error(t, provider.Reload())
+	assert.Equal(t, "changed", provider.Get("VAR1"))
+}
+
+func Test_DotEnvProviderLookup(t *testing.T) {
+	envFilePath, err := createTempEnvFile("VAR1=value1\nEMPTY=")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(envFilePath) }()
+
+	provider, err := NewDotEnvProvider(envFilePath)
+	assert.NoError(t, err)
+
+	value, found, err := provider.Lookup(context.Background(), "EMPTY")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "", value)
+
+	value, found, err = provider.Lookup(context.Background(), "VAR1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value1", value)
+
+	_, found, err = provider.Lookup(context.Background(), "NON_EXISTING_KEY")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
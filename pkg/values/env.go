@@ -1,6 +1,10 @@
 package values
 
-import "os"
+import (
+	"context"
+	"os"
+	"strings"
+)
 
 type EnvProvider struct {
 }
@@ -12,3 +16,33 @@ func NewEnvProvider() *EnvProvider {
 func (p *EnvProvider) Get(key string) string {
 	return os.Getenv(key)
 }
+
+// Keys returns the names of all environment variables
+func (p *EnvProvider) Keys() []string {
+	environ := os.Environ()
+	keys := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		keys = append(keys, strings.SplitN(kv, "=", 2)[0])
+	}
+	return keys
+}
+
+// Name returns the name of the provider
+func (p *EnvProvider) Name() string {
+	return "env"
+}
+
+// Unset removes the environment variable, so it is not inherited by child processes
+func (p *EnvProvider) Unset(key string) error {
+	return os.Unsetenv(key)
+}
+
+// Lookup returns the value of the environment variable and whether it is set, even to an empty value
+func (p *EnvProvider) Lookup(ctx context.Context, key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+
+	value, ok := os.LookupEnv(key)
+	return value, ok, nil
+}
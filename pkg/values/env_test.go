@@ -1,6 +1,7 @@
 package values
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
@@ -32,3 +33,48 @@ func TestEnvProvider_GetWithoutValue(t *testing.T) {
 	result := provider.Get(key)
 	assert.Equal(t, "", result)
 }
+
+func TestEnvProvider_Keys(t *testing.T) {
+	_ = os.Setenv("LISTED_KEY", "value")
+
+	provider := NewEnvProvider()
+
+	assert.Contains(t, provider.Keys(), "LISTED_KEY")
+}
+
+func TestEnvProvider_Unset(t *testing.T) {
+	_ = os.Setenv("UNSET_KEY", "value")
+
+	provider := NewEnvProvider()
+
+	assert.NoError(t, provider.Unset("UNSET_KEY"))
+
+	_, ok := os.LookupEnv("UNSET_KEY")
+	assert.False(t, ok)
+}
+
+func TestEnvProvider_Name(t *testing.T) {
+	assert.Equal(t, "env", NewEnvProvider().Name())
+}
+
+func TestEnvProvider_Lookup(t *testing.T) {
+	_ = os.Setenv("LOOKUP_EMPTY_KEY", "")
+	_ = os.Unsetenv("LOOKUP_UNSET_KEY")
+	defer func() { _ = os.Unsetenv("LOOKUP_EMPTY_KEY") }()
+
+	provider := NewEnvProvider()
+
+	value, found, err := provider.Lookup(context.Background(), "LOOKUP_EMPTY_KEY")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "", value)
+
+	_, found, err = provider.Lookup(context.Background(), "LOOKUP_UNSET_KEY")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = provider.Lookup(ctx, "LOOKUP_EMPTY_KEY")
+	assert.Error(t, err)
+}
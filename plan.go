@@ -0,0 +1,286 @@
+package gocfg
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldKind is the way a struct field is loaded
+type fieldKind int
+
+const (
+	// valueField is parsed from a single value
+	valueField fieldKind = iota
+	// structField is a nested struct walked field by field
+	structField
+	// structPtrField is a pointer to a nested struct, allocated on demand
+	structPtrField
+	// structSliceField is a slice of structs filled from indexed keys
+	structSliceField
+	// prefixMapField is a map filled from every key starting with the field key
+	prefixMapField
+)
+
+// typePlan is the compiled plan for loading a struct type: its fields, except skipped ones, with their tags
+// parsed and parsers resolved. Plans are cached per ConfigManager and shared by concurrent calls.
+type typePlan struct {
+	fields []*fieldPlan
+}
+
+// fieldPlan is the compiled, scope-independent part of loading a struct field
+type fieldPlan struct {
+	index     int
+	name      string
+	typ       reflect.Type
+	kind      fieldKind
+	anonymous bool
+	// spec holds everything but the path, the group and the prefixes of keys, which depend on the scope
+	spec        fieldSpec
+	groupPrefix string
+	// deriveKey is set for fields without a key, whose key is derived from the names of the fields leading to them
+	deriveKey  bool
+	splitWords bool
+
+	example     string
+	description string
+	title       string
+}
+
+// typePlan returns the plan of struct type t, compiling it on first use
+func (c *ConfigManager) typePlan(t reflect.Type) *typePlan {
+	if plan, ok := c.plans.Load(t); ok {
+		return plan.(*typePlan)
+	}
+
+	plan, _ := c.plans.LoadOrStore(t, c.compileTypePlan(t))
+	return plan.(*typePlan)
+}
+
+// resetPlans drops the compiled plans, as they depend on the settings of the ConfigManager
+func (c *ConfigManager) resetPlans() {
+	c.plans = new(sync.Map)
+}
+
+func (c *ConfigManager) compileTypePlan(t reflect.Type) *typePlan {
+	plan := &typePlan{fields: make([]*fieldPlan, 0, t.NumField())}
+	for i := 0; i < t.NumField(); i++ {
+		if field, ok := c.compileField(t.Field(i), i); ok {
+			plan.fields = append(plan.fields, field)
+		}
+	}
+	return plan
+}
+
+// compileField compiles the plan of a struct field, reporting false for skipped fields.
+// Invalid tags, unsupported types and unparsable defaults are recorded in the spec, to be returned on every load.
+func (c *ConfigManager) compileField(field reflect.StructField, index int) (*fieldPlan, bool) {
+	var (
+		tag, tagErr = c.parseKeyTag(field.Tag.Get(c.structKeyTag))
+		key         = c.tagKey(tag.keys[0])
+		// Unexported fields cannot be set, so they are skipped, e.g. the internals of third-party structs.
+		// Embedded structs are kept, as their exported fields can be set.
+		unexported  = field.PkgPath != "" && !(field.Anonymous && isStruct(field.Type))
+		derivesKeys = c.namingStrategy != nil || c.structSplitWordsTag != ""
+	)
+
+	if key == skipKey || unexported || (c.structIgnoredTag != "" && parseBoolTag(field.Tag.Get(c.structIgnoredTag))) {
+		return nil, false
+	}
+
+	valueType := field.Type
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	rules, rulesErr := parseValidationRules(field.Tag.Get(c.structValidateTag), valueType)
+
+	var aliases []string
+	for _, alias := range tag.keys[1:] {
+		if alias = c.tagKey(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	emptyValues, emptyErr := tag.emptyValues()
+	minCount, minErr := tag.intValue(tagOptionMinCount, 0)
+	maxCount, maxErr := tag.intValue(tagOptionMaxCount, -1)
+
+	required, requiredErr := c.isRequired(field, tag)
+
+	var conflictErr error
+	if tag.has(c.structAllowEmptyTag) && required {
+		conflictErr = fmt.Errorf("options %s and %s conflict", c.structAllowEmptyTag, tagOptionRequired)
+	}
+
+	separator := tag.value(tagOptionSeparator, field.Tag.Get(c.structSeparatorTag))
+	if separator == "" {
+		separator = defaultSeparator
+	}
+
+	kvSeparator := tag.value(tagOptionKeyValueSeparator, field.Tag.Get(c.structKeyValueSeparatorTag))
+	if kvSeparator == "" {
+		kvSeparator = defaultKeyValueSeparator
+	}
+
+	// Only the first error is reported
+	for _, err := range []error{requiredErr, emptyErr, minErr, maxErr, conflictErr, rulesErr} {
+		if tagErr == nil {
+			tagErr = err
+		}
+	}
+
+	plan := &fieldPlan{
+		index:     index,
+		name:      field.Name,
+		typ:       field.Type,
+		anonymous: field.Anonymous,
+		spec: fieldSpec{
+			key:          key,
+			aliases:      aliases,
+			allowEmpty:   tag.has(c.structAllowEmptyTag) || (c.optionalByDefault && !required),
+			secret:       tag.has(c.structSecretTag) || tag.has(tagOptionSensitive),
+			file:         c.fileIndirection || tag.has(tagOptionFile),
+			expand:       c.expand || tag.has(tagOptionExpand),
+			unset:        tag.has(tagOptionUnset),
+			prefix:       tag.has(tagOptionPrefix),
+			separator:    separator,
+			kvSeparator:  kvSeparator,
+			minCount:     minCount,
+			maxCount:     maxCount,
+			defaultValue: field.Tag.Get(c.structDefaultTag),
+			rules:        rules,
+			tagErr:       tagErr,
+			emptyValues:  emptyValues,
+		},
+		groupPrefix: field.Tag.Get(c.structPrefixTag),
+		deriveKey:   key == "" && derivesKeys,
+		splitWords:  parseBoolTag(field.Tag.Get(c.structSplitWordsTag)),
+		example:     field.Tag.Get(c.structExampleTag),
+		description: field.Tag.Get(c.structDescriptionTag),
+		title:       field.Tag.Get(c.structTitleTag),
+	}
+
+	switch {
+	case isStruct(field.Type):
+		plan.kind = structField
+	case isStructPtr(field.Type):
+		plan.kind = structPtrField
+	case isStructSlice(field.Type):
+		plan.kind = structSliceField
+	case plan.spec.prefix:
+		plan.kind = prefixMapField
+		c.compileParsers(&plan.spec, field.Type)
+	default:
+		plan.kind = valueField
+		c.compileParsers(&plan.spec, field.Type)
+	}
+
+	return plan, true
+}
+
+// tagKey returns a key or alias set in the key tag, trimmed and upper-cased if configured
+func (c *ConfigManager) tagKey(key string) string {
+	key = strings.TrimSpace(key)
+	if c.upperCaseKeys {
+		key = strings.ToUpper(key)
+	}
+	return key
+}
+
+// compileParsers resolves the parsers of a value or prefix map field of type t and parses its default value,
+// unless it is to be expanded first. Defaults holding ${VAR} references are not parsed either: they are written
+// for expansion, which may be enabled for some loads only, e.g. by UseExpansion on a derived ConfigManager.
+func (c *ConfigManager) compileParsers(spec *fieldSpec, t reflect.Type) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	spec.parser, _ = c.getFieldParser(t, *spec)
+
+	if spec.prefix {
+		if t.Kind() != reflect.Map {
+			spec.parser = nil
+			return
+		}
+
+		keyParser, keyOk := c.getFieldParser(t.Key(), *spec)
+		elemParser, elemOk := c.getFieldParser(t.Elem(), *spec)
+		if !keyOk || !elemOk {
+			spec.parser = nil
+			return
+		}
+		spec.keyParser, spec.elemParser = keyParser, elemParser
+	}
+
+	if spec.parser != nil && spec.defaultValue != "" && !spec.expand && !strings.Contains(spec.defaultValue, "${") {
+		_, spec.defaultErr = spec.parser(spec.defaultValue)
+	}
+}
+
+// specInScope returns the spec of a compiled field found in the given scope
+func (c *ConfigManager) specInScope(plan *fieldPlan, scope structScope) fieldSpec {
+	spec := plan.spec
+
+	names := append(scope.names[:len(scope.names):len(scope.names)], plan.name)
+	if c.prefixNestedKeys {
+		switch {
+		case spec.key != "":
+			names[len(names)-1] = spec.key
+		case plan.anonymous && (plan.kind == structField || plan.kind == structPtrField):
+			names = names[:len(names)-1]
+		}
+	}
+
+	spec.group = structScope{
+		path:      joinFieldPath(scope.path, plan.name),
+		keyPrefix: scope.keyPrefix + plan.groupPrefix,
+		names:     names,
+		enclosing: scope.enclosing,
+	}
+	if plan.groupPrefix != "" {
+		spec.group.names = nil
+	}
+	spec.path = spec.group.path
+
+	key := spec.key
+	switch {
+	case plan.deriveKey && len(names) > 0:
+		if c.namingStrategy != nil {
+			key = c.namingStrategy(names)
+		} else {
+			key = upperCaseKey(names, plan.splitWords)
+		}
+	case c.prefixNestedKeys && key != "" && len(scope.names) > 0:
+		spec.altKey = prefixKey(scope.keyPrefix, key)
+		key = upperCaseKey(names, false)
+	}
+	spec.key = prefixKey(scope.keyPrefix, key)
+
+	if len(spec.aliases) > 0 {
+		spec.aliases = make([]string, len(plan.spec.aliases))
+		for i, alias := range plan.spec.aliases {
+			spec.aliases[i] = prefixKey(scope.keyPrefix, alias)
+		}
+	}
+
+	return spec
+}
+
+// compileError returns the error found when compiling the field: invalid tags, an unsupported type,
+// or a default value that cannot be parsed, which is only reported when defaults are used
+func (c *ConfigManager) compileError(plan *fieldPlan, spec fieldSpec, fieldType reflect.Type) error {
+	if spec.tagErr != nil {
+		return &InvalidTagError{FieldError{Path: spec.path, Key: spec.key, Err: spec.tagErr}}
+	}
+
+	if (plan.kind == valueField || plan.kind == prefixMapField) && spec.parser == nil {
+		return &UnsupportedTypeError{FieldError: spec.fieldError("", "", nil), Type: fieldType}
+	}
+
+	if spec.defaultErr != nil && c.useDefaults {
+		return &ParseError{spec.fieldError(spec.defaultValue, defaultProviderName, spec.defaultErr)}
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package gocfg
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jagerente/gocfg/pkg/parsers"
+	"github.com/Jagerente/gocfg/pkg/values"
+	"github.com/stretchr/testify/assert"
+)
+
+type planRedisConfig struct {
+	Host     string `env:"HOST" default:"localhost"`
+	Port     uint16 `env:"PORT" default:"6379"`
+	Password string `env:"PASSWORD|PASS,secret,omitempty"`
+}
+
+type planConfig struct {
+	LogLevel string            `env:"PLAN_LOG_LEVEL" default:"info" validate:"oneof=debug info warn error"`
+	Timeout  time.Duration     `env:"PLAN_TIMEOUT" default:"5s"`
+	Hosts    []string          `env:"PLAN_HOSTS" default:"a,b,c"`
+	Labels   map[string]string `env:"PLAN_LABELS" default:"env:prod"`
+	Ratio    *float64          `env:"PLAN_RATIO,omitempty"`
+	Redis    planRedisConfig   `envPrefix:"PLAN_REDIS_"`
+	Replica  *planRedisConfig  `envPrefix:"PLAN_REPLICA_" env:",omitempty"`
+	Internal string            `env:"-"`
+}
+
+func Test_typePlan(t *testing.T) {
+	cfgManager := NewDefault()
+	typ := reflect.TypeOf(planConfig{})
+
+	plan := cfgManager.typePlan(typ)
+	assert.Same(t, plan, cfgManager.typePlan(typ))
+	assert.Len(t, plan.fields, 7)
+
+	redis := plan.fields[5]
+	assert.Equal(t, "Redis", redis.name)
+	assert.Equal(t, structField, redis.kind)
+	assert.Equal(t, "PLAN_REDIS_", redis.groupPrefix)
+	assert.Equal(t, structPtrField, plan.fields[6].kind)
+
+	hosts := plan.fields[2]
+	assert.Equal(t, valueField, hosts.kind)
+	assert.NotNil(t, hosts.spec.parser)
+	assert.NoError(t, hosts.spec.defaultErr)
+
+	group := cfgManager.specInScope(redis, structScope{}).group
+	spec := cfgManager.specInScope(cfgManager.typePlan(redis.typ).fields[2], group)
+	assert.Equal(t, "Redis.Password", spec.path)
+	assert.Equal(t, "PLAN_REDIS_PASSWORD", spec.key)
+	assert.Equal(t, []string{"PLAN_REDIS_PASS"}, spec.aliases)
+	assert.Equal(t, []string{"PASS"}, cfgManager.typePlan(redis.typ).fields[2].spec.aliases)
+
+	cfgManager.UseNamingStrategy(ScreamingSnakeCase)
+	assert.NotSame(t, plan, cfgManager.typePlan(typ))
+}
+
+func Test_typePlan_CompileErrors(t *testing.T) {
+	_ = os.Setenv("PLAN_DEFAULT_PORT", "8080")
+	_ = os.Unsetenv("PLAN_UNSUPPORTED")
+	defer func() { _ = os.Unsetenv("PLAN_DEFAULT_PORT") }()
+
+	t.Run("unparsable default", func(t *testing.T) {
+		type TestConfig struct {
+			Port int `env:"PLAN_DEFAULT_PORT" default:"eighty"`
+		}
+
+		err := NewDefault().Silent().Unmarshal(new(TestConfig))
+
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "eighty", parseErr.Value)
+		assert.Equal(t, "default", parseErr.Provider)
+
+		// Defaults are not parsed when they are not used
+		cfg := new(TestConfig)
+		err = NewEmpty().
+			AddParserProviders(parsers.NewDefaultParserProvider()).
+			AddValueProviders(values.NewEnvProvider()).
+			Unmarshal(cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, 8080, cfg.Port)
+	})
+
+	t.Run("default with references", func(t *testing.T) {
+		type TestConfig struct {
+			Port int `env:"PLAN_DEFAULT_PORT" default:"${PLAN_FALLBACK_PORT:-5432}"`
+		}
+
+		cfg := new(TestConfig)
+		err := NewDefault().Silent().Unmarshal(cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, 8080, cfg.Port)
+
+		_ = os.Unsetenv("PLAN_DEFAULT_PORT")
+		defer func() { _ = os.Setenv("PLAN_DEFAULT_PORT", "8080") }()
+
+		err = NewDefault().Silent().Unmarshal(new(TestConfig))
+
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "${PLAN_FALLBACK_PORT:-5432}", parseErr.Value)
+	})
+
+	t.Run("unsupported type without value", func(t *testing.T) {
+		type TestConfig struct {
+			Unsupported complex64 `env:"PLAN_UNSUPPORTED,omitempty"`
+			Flags       []int     `env:"PLAN_UNSUPPORTED_,prefix"`
+		}
+
+		err := NewDefault().Silent().CollectErrors().Unmarshal(new(TestConfig))
+
+		var multiErr *MultiError
+		assert.True(t, errors.As(err, &multiErr))
+		assert.Len(t, multiErr.Errors, 2)
+		assert.True(t, errors.As(multiErr.Errors[0], new(*UnsupportedTypeError)))
+		assert.True(t, errors.As(multiErr.Errors[1], new(*UnsupportedTypeError)))
+	})
+}
+
+func Test_Unmarshal_Concurrent(t *testing.T) {
+	_ = os.Setenv("PLAN_REDIS_PASS", "hunter2")
+	defer func() { _ = os.Unsetenv("PLAN_REDIS_PASS") }()
+
+	cfgManager := NewDefault().Silent()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			cfg := new(planConfig)
+			assert.NoError(t, cfgManager.Unmarshal(cfg))
+			assert.Equal(t, "hunter2", cfg.Redis.Password)
+			assert.Nil(t, cfg.Replica)
+
+			doc := NewDoc()
+			cfgManager.parseDocGroup(doc, cfg)
+			assert.Len(t, doc.Groups, 2)
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	cfgManager := NewDefault().Silent()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := cfgManager.Unmarshal(new(planConfig)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshal_Uncached(b *testing.B) {
+	cfgManager := NewDefault().Silent()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cfgManager.resetPlans()
+		if err := cfgManager.Unmarshal(new(planConfig)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseDocGroup(b *testing.B) {
+	cfgManager := NewDefault()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cfgManager.parseDocGroup(NewDoc(), new(planConfig))
+	}
+}
+
+func BenchmarkParseDocGroup_Uncached(b *testing.B) {
+	cfgManager := NewDefault()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cfgManager.resetPlans()
+		cfgManager.parseDocGroup(NewDoc(), new(planConfig))
+	}
+}
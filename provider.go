@@ -0,0 +1,90 @@
+package gocfg
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContextValueProvider is a value provider that can report failures and respects cancellation,
+// e.g. a client of a remote configuration store
+type ContextValueProvider interface {
+	// Lookup returns the value of key and whether the key is set. A non-nil error aborts loading.
+	Lookup(ctx context.Context, key string) (value string, found bool, err error)
+}
+
+// Unsetter is an optional interface for value providers that can remove keys,
+// used for fields marked with the unset option, e.g. `env:"DB_PASSWORD,unset"`
+type Unsetter interface {
+	Unset(key string) error
+}
+
+// AdaptValueProvider turns a ValueProvider into a ContextValueProvider.
+// Providers already implementing ContextValueProvider are returned as is. Otherwise, keys with an empty value
+// are reported as not found, and lookups fail once ctx is done.
+func AdaptValueProvider(p ValueProvider) ContextValueProvider {
+	if contextProvider, ok := p.(ContextValueProvider); ok {
+		return contextProvider
+	}
+	return &valueProviderAdapter{provider: p}
+}
+
+type valueProviderAdapter struct {
+	provider ValueProvider
+}
+
+func (a *valueProviderAdapter) Lookup(ctx context.Context, key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+
+	value := a.provider.Get(key)
+
+	return value, value != "", nil
+}
+
+// underlyingProvider returns the provider wrapped by AdaptValueProvider, or p itself,
+// so the optional interfaces of the original provider are found
+func underlyingProvider(p ContextValueProvider) interface{} {
+	if adapter, ok := p.(*valueProviderAdapter); ok {
+		return adapter.provider
+	}
+	return p
+}
+
+// providerName returns a human-readable name of a value provider
+func providerName(p ContextValueProvider) string {
+	provider := underlyingProvider(p)
+	if named, ok := provider.(NamedValueProvider); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", provider)
+}
+
+// getValue retrieves the non-empty value of the field at path by key from registered value providers,
+// along with the name of the provider that supplied it. A failed lookup is returned as a *ProviderError.
+func (c *ConfigManager) getValue(ctx context.Context, path, key string) (string, string, error) {
+	value, provider, _, err := c.lookupValue(ctx, path, key, false)
+	return value, provider, err
+}
+
+// lookupValue works like getValue and also reports whether a provider has the key.
+// When stopAtEmpty is set, a provider having the key set to an empty value wins; otherwise, it is skipped.
+func (c *ConfigManager) lookupValue(ctx context.Context, path, key string, stopAtEmpty bool) (string, string, bool, error) {
+	for _, p := range c.valueProviders {
+		value, found, err := p.Lookup(ctx, key)
+		if err != nil {
+			return "", "", false, &ProviderError{FieldError{Path: path, Key: key, Provider: providerName(p), Err: err}}
+		}
+
+		if found && (value != "" || stopAtEmpty) {
+			return value, providerName(p), true, nil
+		}
+	}
+	return "", "", false, nil
+}
+
+// hasValue reports whether p has a non-empty value for key. Failed lookups are treated as unset.
+func hasValue(ctx context.Context, p ContextValueProvider, key string) bool {
+	value, found, err := p.Lookup(ctx, key)
+	return err == nil && found && value != ""
+}
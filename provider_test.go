@@ -0,0 +1,138 @@
+package gocfg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/Jagerente/gocfg/pkg/parsers"
+	"github.com/Jagerente/gocfg/pkg/values"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapContextProvider struct {
+	values map[string]string
+	err    error
+	keys   []string
+}
+
+func (p *mapContextProvider) Lookup(ctx context.Context, key string) (string, bool, error) {
+	p.keys = append(p.keys, key)
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+	if p.err != nil {
+		return "", false, p.err
+	}
+	value, ok := p.values[key]
+	return value, ok, nil
+}
+
+func (p *mapContextProvider) Name() string {
+	return "remote"
+}
+
+func Test_UnmarshalContext(t *testing.T) {
+	type TestConfig struct {
+		Host string `env:"CTX_HOST"`
+		Port int    `env:"CTX_PORT" default:"80"`
+	}
+
+	_ = os.Setenv("CTX_HOST", "env-host")
+	_ = os.Unsetenv("CTX_PORT")
+	defer func() { _ = os.Unsetenv("CTX_HOST") }()
+
+	t.Run("providers share one chain", func(t *testing.T) {
+		remote := &mapContextProvider{values: map[string]string{"CTX_HOST": "remote-host", "CTX_PORT": "8080"}}
+
+		cfg := new(TestConfig)
+		err := NewDefault().AddContextValueProviders(remote).UnmarshalContext(context.Background(), cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &TestConfig{Host: "env-host", Port: 8080}, cfg)
+	})
+
+	t.Run("provider error aborts loading", func(t *testing.T) {
+		errUnavailable := errors.New("store unavailable")
+		remote := &mapContextProvider{err: errUnavailable}
+
+		cfg := new(TestConfig)
+		err := NewEmpty().
+			UseDefaults().
+			CollectErrors().
+			AddParserProviders(parsers.NewDefaultParserProvider()).
+			AddContextValueProviders(remote).
+			UnmarshalContext(context.Background(), cfg)
+
+		var providerErr *ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "Host", providerErr.Path)
+		assert.Equal(t, "CTX_HOST", providerErr.Key)
+		assert.Equal(t, "remote", providerErr.Provider)
+		assert.True(t, errors.Is(err, errUnavailable))
+		assert.EqualError(t, err, "failed to look up CTX_HOST in remote: store unavailable")
+		assert.Equal(t, []string{"CTX_HOST"}, remote.keys)
+		assert.Equal(t, 0, cfg.Port)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := NewDefault().UnmarshalContext(ctx, new(TestConfig))
+
+		var providerErr *ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "env", providerErr.Provider)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("error in optional group aborts loading", func(t *testing.T) {
+		type Group struct {
+			Value string `env:"CTX_GROUP_VALUE"`
+		}
+		type GroupConfig struct {
+			Group *Group  `env:",omitempty"`
+			Items []Group `env:"CTX_ITEMS,omitempty"`
+		}
+
+		remote := &mapContextProvider{err: errors.New("timeout")}
+
+		err := NewDefault().CollectErrors().AddContextValueProviders(remote).Unmarshal(new(GroupConfig))
+
+		var providerErr *ProviderError
+		assert.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, "CTX_GROUP_VALUE", providerErr.Key)
+	})
+}
+
+type getterProvider map[string]string
+
+func (p getterProvider) Get(key string) string {
+	return p[key]
+}
+
+func Test_AdaptValueProvider(t *testing.T) {
+	provider := AdaptValueProvider(getterProvider{"ADAPTED_KEY": "value", "ADAPTED_EMPTY": ""})
+
+	value, found, err := provider.Lookup(context.Background(), "ADAPTED_KEY")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+
+	_, found, err = provider.Lookup(context.Background(), "ADAPTED_EMPTY")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = provider.Lookup(ctx, "ADAPTED_KEY")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, "gocfg.getterProvider", providerName(provider))
+
+	envProvider := values.NewEnvProvider()
+	assert.Same(t, envProvider, AdaptValueProvider(envProvider))
+	assert.Equal(t, "env", providerName(AdaptValueProvider(envProvider)))
+}
@@ -0,0 +1,141 @@
+package gocfg
+
+import "context"
+
+// NamedValueProvider is an optional interface for value providers that report a human-readable name.
+// Providers without it are named after their Go type.
+type NamedValueProvider interface {
+	Name() string
+}
+
+// ValueLocator is an optional interface for value providers that know where a value is defined
+type ValueLocator interface {
+	// Locate returns the file and the 1-based line defining the key
+	Locate(key string) (file string, line int, ok bool)
+}
+
+// Source is a place a value came from
+type Source struct {
+	// Provider is the name of the value provider, or "default" for default values
+	Provider string `json:"source,omitempty"`
+	// File and Line locate the value, when the provider knows them
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// FieldSource describes where the value of a loaded field came from
+type FieldSource struct {
+	// Path is the dotted Go field path, e.g. RedisConfig.RedisPort
+	Path string
+	Key  string
+	// Source is the winning source of the value
+	Source
+	// DefaultUsed reports whether the value came from the default tag
+	DefaultUsed bool
+	// Shadowed lists lower-priority providers that also had a value for the key
+	Shadowed []Source
+}
+
+// Report describes where the value of every field loaded by UnmarshalWithReport came from.
+// Fields left unset are not part of the report.
+type Report struct {
+	fields []*FieldSource
+}
+
+// Fields returns the sources of all loaded fields, in the order they were loaded
+func (r *Report) Fields() []*FieldSource {
+	return r.fields
+}
+
+// Field returns the source of the field with the given dotted Go field path
+func (r *Report) Field(path string) (*FieldSource, bool) {
+	for _, f := range r.fields {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// Key returns the source of the field loaded by the given key
+func (r *Report) Key(key string) (*FieldSource, bool) {
+	for _, f := range r.fields {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// FromProvider returns the sources of all fields whose value was supplied by the named provider
+func (r *Report) FromProvider(name string) []*FieldSource {
+	var fields []*FieldSource
+	for _, f := range r.fields {
+		if f.Provider == name {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Defaults returns the sources of all fields that fell back to their default value
+func (r *Report) Defaults() []*FieldSource {
+	var fields []*FieldSource
+	for _, f := range r.fields {
+		if f.DefaultUsed {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// UnmarshalWithReport works like Unmarshal and additionally reports which source supplied the value of each field
+func (c *ConfigManager) UnmarshalWithReport(cfg interface{}) (*Report, error) {
+	state := newUnmarshalState(c.collectErrors)
+	state.report = new(Report)
+
+	err := c.unmarshalRoot(context.Background(), cfg, state)
+
+	return state.report, err
+}
+
+// recordSource adds the source of a field to the report. The winner is the default, the file the value was read from,
+// or the value provider that supplied it. Only the providers the value was not looked up in yet are queried
+// for shadowed values: all of them for defaults and files, the lower-priority ones otherwise.
+func (c *ConfigManager) recordSource(ctx context.Context, report *Report, spec fieldSpec, winner Source) {
+	fieldSource := &FieldSource{
+		Path:        spec.path,
+		Key:         spec.key,
+		Source:      winner,
+		DefaultUsed: winner.Provider == defaultProviderName,
+	}
+
+	providers := c.valueProviders
+	if winner.Provider != defaultProviderName && winner.File == "" {
+		// Providers before the winner were looked up first and had no value
+		for i, p := range providers {
+			if providerName(p) == winner.Provider {
+				fieldSource.Source = providerSource(p, spec.key)
+				providers = providers[i+1:]
+				break
+			}
+		}
+	}
+
+	for _, p := range providers {
+		if hasValue(ctx, p, spec.key) {
+			fieldSource.Shadowed = append(fieldSource.Shadowed, providerSource(p, spec.key))
+		}
+	}
+
+	report.fields = append(report.fields, fieldSource)
+}
+
+// providerSource returns the source of the value of key supplied by p
+func providerSource(p ContextValueProvider, key string) Source {
+	source := Source{Provider: providerName(p)}
+	if locator, ok := underlyingProvider(p).(ValueLocator); ok {
+		source.File, source.Line, _ = locator.Locate(key)
+	}
+	return source
+}
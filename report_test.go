@@ -0,0 +1,190 @@
+package gocfg
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/Jagerente/gocfg/pkg/parsers"
+	"github.com/Jagerente/gocfg/pkg/values"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UnmarshalWithReport(t *testing.T) {
+	type RedisConfig struct {
+		Host string `env:"REPORT_REDIS_HOST"`
+	}
+
+	type Upstream struct {
+		URL string `env:"URL"`
+	}
+
+	type TestConfig struct {
+		Host      string       `env:"REPORT_HOST"`
+		Port      int          `env:"REPORT_PORT"`
+		Timeout   int          `env:"REPORT_TIMEOUT" default:"30"`
+		Unset     string       `env:"REPORT_UNSET,omitempty"`
+		Redis     *RedisConfig `env:"REPORT_REDIS,omitempty"`
+		Upstreams []Upstream   `env:"REPORT_UPSTREAMS"`
+	}
+
+	tmpFile, err := os.CreateTemp(".", "test_env_*.env")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	_, err = tmpFile.WriteString("REPORT_HOST=dotenv-host\n# comment\nREPORT_PORT=8080\nREPORT_UPSTREAMS_0_URL=http://a\n")
+	assert.NoError(t, err)
+	_ = tmpFile.Close()
+
+	dotEnvProvider, err := values.NewDotEnvProvider(tmpFile.Name())
+	assert.NoError(t, err)
+
+	_ = os.Setenv("REPORT_HOST", "env-host")
+	_ = os.Unsetenv("REPORT_PORT")
+	_ = os.Unsetenv("REPORT_TIMEOUT")
+	_ = os.Unsetenv("REPORT_UNSET")
+	_ = os.Unsetenv("REPORT_REDIS_HOST")
+	_ = os.Setenv("REPORT_UPSTREAMS_1_URL", "http://b")
+	defer func() {
+		_ = os.Unsetenv("REPORT_HOST")
+		_ = os.Unsetenv("REPORT_UPSTREAMS_1_URL")
+	}()
+
+	cfg := new(TestConfig)
+	report, err := NewDefault().
+		Silent().
+		AddValueProviders(dotEnvProvider).
+		UnmarshalWithReport(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "env-host", cfg.Host)
+	assert.Nil(t, cfg.Redis)
+	assert.Len(t, cfg.Upstreams, 2)
+
+	host, ok := report.Field("Host")
+	assert.True(t, ok)
+	assert.Equal(t, &FieldSource{
+		Path:     "Host",
+		Key:      "REPORT_HOST",
+		Source:   Source{Provider: "env"},
+		Shadowed: []Source{{Provider: "dotenv", File: tmpFile.Name(), Line: 1}},
+	}, host)
+
+	port, ok := report.Key("REPORT_PORT")
+	assert.True(t, ok)
+	assert.Equal(t, Source{Provider: "dotenv", File: tmpFile.Name(), Line: 3}, port.Source)
+	assert.Empty(t, port.Shadowed)
+
+	timeout, ok := report.Field("Timeout")
+	assert.True(t, ok)
+	assert.True(t, timeout.DefaultUsed)
+	assert.Equal(t, "default", timeout.Provider)
+
+	_, ok = report.Field("Unset")
+	assert.False(t, ok)
+
+	_, ok = report.Field("Redis.Host")
+	assert.False(t, ok)
+
+	upstream, ok := report.Field("Upstreams[1].URL")
+	assert.True(t, ok)
+	assert.Equal(t, "REPORT_UPSTREAMS_1_URL", upstream.Key)
+	assert.Equal(t, "env", upstream.Provider)
+
+	assert.Equal(t, []*FieldSource{timeout}, report.Defaults())
+
+	var fromDotEnv []string
+	for _, f := range report.FromProvider("dotenv") {
+		fromDotEnv = append(fromDotEnv, f.Path)
+	}
+	assert.Equal(t, []string{"Port", "Upstreams[0].URL"}, fromDotEnv)
+}
+
+func Test_UnmarshalWithReport_ForceDefaults(t *testing.T) {
+	type TestConfig struct {
+		Port int `env:"REPORT_FORCED_PORT" default:"80"`
+	}
+
+	_ = os.Setenv("REPORT_FORCED_PORT", "8080")
+	defer func() { _ = os.Unsetenv("REPORT_FORCED_PORT") }()
+
+	report, err := NewDefault().Silent().ForceDefaults().UnmarshalWithReport(new(TestConfig))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*FieldSource{{
+		Path:        "Port",
+		Key:         "REPORT_FORCED_PORT",
+		Source:      Source{Provider: "default"},
+		DefaultUsed: true,
+		Shadowed:    []Source{{Provider: "env"}},
+	}}, report.Fields())
+}
+
+type reportCountingProvider struct {
+	values  map[string]string
+	lookups map[string]int
+}
+
+func (p *reportCountingProvider) Get(key string) string {
+	return p.values[key]
+}
+
+func (p *reportCountingProvider) Lookup(_ context.Context, key string) (string, bool, error) {
+	p.lookups[key]++
+	value, ok := p.values[key]
+	return value, ok, nil
+}
+
+func (p *reportCountingProvider) Keys() []string {
+	keys := make([]string, 0, len(p.values))
+	for k := range p.values {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (p *reportCountingProvider) Name() string {
+	return "counting"
+}
+
+func Test_UnmarshalWithReport_Winner(t *testing.T) {
+	type TestConfig struct {
+		Host   string            `env:"REPORT_WINNER_HOST"`
+		Labels map[string]string `env:"REPORT_WINNER_LABEL_,prefix"`
+	}
+
+	provider := &reportCountingProvider{
+		values: map[string]string{
+			"REPORT_WINNER_HOST":       "counting-host",
+			"REPORT_WINNER_LABEL_TEAM": "core",
+		},
+		lookups: make(map[string]int),
+	}
+
+	_ = os.Setenv("REPORT_WINNER_LABEL_TEAM", "env-core")
+	defer func() { _ = os.Unsetenv("REPORT_WINNER_LABEL_TEAM") }()
+
+	cfg := new(TestConfig)
+	report, err := NewEmpty().
+		AddParserProviders(parsers.NewDefaultParserProvider()).
+		AddValueProviders(provider, values.NewEnvProvider()).
+		UnmarshalWithReport(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, provider.lookups["REPORT_WINNER_HOST"])
+	assert.Equal(t, 1, provider.lookups["REPORT_WINNER_LABEL_TEAM"])
+
+	host, ok := report.Field("Host")
+	assert.True(t, ok)
+	assert.Equal(t, "counting", host.Provider)
+	assert.Empty(t, host.Shadowed)
+
+	label, ok := report.Key("REPORT_WINNER_LABEL_TEAM")
+	assert.True(t, ok)
+	assert.Equal(t, &FieldSource{
+		Path:     "Labels[TEAM]",
+		Key:      "REPORT_WINNER_LABEL_TEAM",
+		Source:   Source{Provider: "counting"},
+		Shadowed: []Source{{Provider: "env"}},
+	}, label)
+}
@@ -0,0 +1,203 @@
+package gocfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keyTag is a parsed key tag following the KEY|ALIAS,option,name=value grammar, e.g. `env:"LABELS,omitempty,sep=;"`
+type keyTag struct {
+	// keys are the key followed by its deprecated aliases, untrimmed and unprefixed
+	keys []string
+	// options maps the names of the given options to their values, empty for flag options
+	options map[string]string
+}
+
+// parseKeyTag splits the key tag into keys and options. Unknown, repeated and malformed options are an error;
+// the keys are returned along with it, so the error can name the field key.
+func (c *ConfigManager) parseKeyTag(tag string) (keyTag, error) {
+	parts := strings.Split(tag, ",")
+
+	parsed := keyTag{
+		keys:    strings.Split(parts[0], aliasSeparator),
+		options: make(map[string]string),
+	}
+
+	for _, opt := range parts[1:] {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			continue
+		}
+
+		name, value, hasValue := opt, "", false
+		if i := strings.Index(opt, "="); i >= 0 {
+			name, value, hasValue = opt[:i], opt[i+1:], true
+		}
+
+		takesValue, known := c.tagOptionTakesValue(name)
+		switch {
+		case !known:
+			return parsed, fmt.Errorf("unknown option %q", name)
+		case takesValue && value == "":
+			return parsed, fmt.Errorf("option %q requires a value, e.g. %s=value", name, name)
+		case !takesValue && hasValue:
+			return parsed, fmt.Errorf("option %q does not take a value", name)
+		}
+
+		if _, ok := parsed.options[name]; ok {
+			return parsed, fmt.Errorf("duplicate option %q", name)
+		}
+		parsed.options[name] = value
+	}
+
+	return parsed, nil
+}
+
+// tagOptionTakesValue reports whether name is a known option of the key tag and whether it is a name=value option
+func (c *ConfigManager) tagOptionTakesValue(name string) (takesValue, known bool) {
+	switch name {
+	case c.structAllowEmptyTag, c.structSecretTag, tagOptionSensitive, tagOptionRequired, tagOptionNotEmpty,
+		tagOptionFile, tagOptionExpand, tagOptionUnset, tagOptionPrefix:
+		return false, true
+	case tagOptionSeparator, tagOptionKeyValueSeparator, tagOptionMinCount, tagOptionMaxCount, tagOptionEmpty:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// has reports whether the flag or name=value option is given
+func (t keyTag) has(name string) bool {
+	_, ok := t.options[name]
+	return ok
+}
+
+// value returns the value of a name=value option, or def if the option is absent
+func (t keyTag) value(name, def string) string {
+	if value, ok := t.options[name]; ok {
+		return value
+	}
+	return def
+}
+
+// intValue returns the integer value of a name=value option, or def if the option is absent
+func (t keyTag) intValue(name string, def int) (int, error) {
+	value, ok := t.options[name]
+	if !ok {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def, fmt.Errorf("invalid %s option %q: must be an integer", name, value)
+	}
+	return n, nil
+}
+
+// emptyValues returns the value of the empty option, which defaults to unset, or reject for fields marked with notEmpty
+func (t keyTag) emptyValues() (string, error) {
+	emptyValues := t.value(tagOptionEmpty, emptyValueUnset)
+	if emptyValues != emptyValueUnset && emptyValues != emptyValueAccept && emptyValues != emptyValueReject {
+		return "", fmt.Errorf("invalid %s option %q: must be %s, %s or %s", tagOptionEmpty, emptyValues, emptyValueUnset, emptyValueAccept, emptyValueReject)
+	}
+
+	if t.has(tagOptionNotEmpty) {
+		if t.has(tagOptionEmpty) && emptyValues != emptyValueReject {
+			return "", fmt.Errorf("options %s and %s=%s conflict", tagOptionNotEmpty, tagOptionEmpty, emptyValues)
+		}
+		emptyValues = emptyValueReject
+	}
+
+	return emptyValues, nil
+}
+
+// TagNames holds the names of the struct tags read by ConfigManager, so they can be remapped when they clash with
+// tags of other libraries used on the same structs. An empty name disables the tag.
+type TagNames struct {
+	// Key is the tag holding the key, its deprecated aliases and options, e.g. `env:"PORT,omitempty"`
+	Key string
+	// Default is the tag holding the default value
+	Default string
+	// Example is the tag holding an example value for documentation
+	Example string
+	// OmitEmpty is the option of the key tag allowing the field to be empty
+	OmitEmpty string
+	// Description is the tag describing the field in documentation
+	Description string
+	// Title is the tag holding the title of a nested struct in documentation
+	Title string
+	// Secret is the option of the key tag marking the field as secret
+	Secret string
+	// Prefix is the tag holding the key prefix of a nested struct
+	Prefix string
+	// Validate is the tag holding the validation rules
+	Validate string
+	// Required is the tag marking the field as required with a boolean, e.g. `required:"true"`,
+	// in addition to the required option of the key tag
+	Required string
+	// Separator is the tag holding the separator of slice and map elements, used when the sep option is absent
+	Separator string
+	// KeyValueSeparator is the tag holding the separator of map keys and values, used when the kvsep option is absent
+	KeyValueSeparator string
+	// SplitWords is the tag marking a field without a key to have its key derived from its name split into words,
+	// e.g. `split_words:"true"` turns MaxConns into MAX_CONNS. Once set, other fields without a key get
+	// their upper-cased name, e.g. MAXCONNS. Keys of nested fields are prefixed with the names of the enclosing fields.
+	// A naming strategy takes priority.
+	SplitWords string
+	// Ignored is the tag marking a field to be skipped with a boolean, e.g. `ignored:"true"`, in addition to the "-" key
+	Ignored string
+	// UpperCaseKeys upper-cases the keys and aliases set in the key tag, e.g. `envconfig:"manual_override_1"`
+	// is read from MANUAL_OVERRIDE_1
+	UpperCaseKeys bool
+	// OptionalByDefault makes fields optional, as if marked with omitempty, unless they are marked as required
+	OptionalByDefault bool
+	// PrefixNestedKeys prefixes keys set in tags of nested fields with the names of the enclosing fields and falls back
+	// to the bare key, e.g. `envconfig:"PORT"` in a field Server is read from SERVER_PORT, then PORT.
+	// A key set on a nested struct replaces its name, and embedded structs add no name.
+	PrefixNestedKeys bool
+}
+
+// DefaultTagNames returns the tag names used by NewEmpty and NewDefault
+func DefaultTagNames() TagNames {
+	return TagNames{
+		Key:         structKeyTag,
+		Default:     structDefaultTag,
+		Example:     structExampleTag,
+		OmitEmpty:   structAllowEmptyTag,
+		Description: structDescriptionTag,
+		Title:       structTitleTag,
+		Secret:      structSecretTag,
+		Prefix:      structPrefixTag,
+		Validate:    structValidateTag,
+	}
+}
+
+// EnvconfigTagNames returns the tag names reading structs written for github.com/kelseyhightower/envconfig:
+// `envconfig:"KEY"`, `default:"value"`, `required:"true"`, `split_words:"true"`, `ignored:"true"`
+// and `desc:"description"`. Fields are optional unless required, keys are upper-cased, fields without a key
+// get their upper-cased name, and keys of nested fields are prefixed with the names of the enclosing fields.
+func EnvconfigTagNames() TagNames {
+	names := DefaultTagNames()
+	names.Key = "envconfig"
+	names.Description = "desc"
+	names.Required = "required"
+	names.SplitWords = "split_words"
+	names.Ignored = "ignored"
+	names.UpperCaseKeys = true
+	names.OptionalByDefault = true
+	names.PrefixNestedKeys = true
+	return names
+}
+
+// CaarlosEnvTagNames returns the tag names reading structs written for github.com/caarlos0/env:
+// `env:"KEY,required,notEmpty"`, `envDefault:"value"`, `envSeparator:";"`, `envKeyValSeparator:"="`
+// and `envPrefix:"PREFIX_"`. Fields are optional unless required.
+func CaarlosEnvTagNames() TagNames {
+	names := DefaultTagNames()
+	names.Default = "envDefault"
+	names.Separator = "envSeparator"
+	names.KeyValueSeparator = "envKeyValSeparator"
+	names.OptionalByDefault = true
+	return names
+}
@@ -0,0 +1,423 @@
+package gocfg
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseKeyTag(t *testing.T) {
+	c := NewEmpty()
+
+	tag, err := c.parseKeyTag("LABELS|OLD_LABELS, omitempty,sep=;,kvsep==,")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"LABELS", "OLD_LABELS"}, tag.keys)
+	assert.Equal(t, map[string]string{"omitempty": "", "sep": ";", "kvsep": "="}, tag.options)
+	assert.True(t, tag.has("omitempty"))
+	assert.False(t, tag.has("secret"))
+	assert.Equal(t, ";", tag.value("sep", ","))
+	assert.Equal(t, ":", tag.value("min", ":"))
+
+	tag, err = c.parseKeyTag("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{""}, tag.keys)
+	assert.Empty(t, tag.options)
+
+	testCases := map[string]string{
+		"KEY,omitempty,bogus":  `unknown option "bogus"`,
+		"NO_OMITEMPTY,cache":   `unknown option "cache"`,
+		"KEY,omitEmpty":        `unknown option "omitEmpty"`,
+		"KEY,sep":              `option "sep" requires a value, e.g. sep=value`,
+		"KEY,sep=":             `option "sep" requires a value, e.g. sep=value`,
+		"KEY,secret=true":      `option "secret" does not take a value`,
+		"KEY,file,file":        `duplicate option "file"`,
+		"KEY,sensitive,unset=": `option "unset" does not take a value`,
+	}
+
+	for tag, expected := range testCases {
+		_, err := c.parseKeyTag(tag)
+		assert.EqualError(t, err, expected, tag)
+	}
+}
+
+func Test_parseKeyTag_CustomOptionNames(t *testing.T) {
+	c := NewEmpty()
+	c.structAllowEmptyTag = "optional"
+
+	tag, err := c.parseKeyTag("KEY,optional")
+	assert.NoError(t, err)
+	assert.True(t, tag.has("optional"))
+
+	_, err = c.parseKeyTag("KEY,omitempty")
+	assert.EqualError(t, err, `unknown option "omitempty"`)
+}
+
+func Test_keyTag_intValue(t *testing.T) {
+	tag := keyTag{options: map[string]string{"min": "2", "max": "many"}}
+
+	n, err := tag.intValue("min", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = tag.intValue("absent", -1)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, n)
+
+	_, err = tag.intValue("max", -1)
+	assert.EqualError(t, err, `invalid max option "many": must be an integer`)
+}
+
+func Test_keyTag_emptyValues(t *testing.T) {
+	testCases := []struct {
+		options  map[string]string
+		expected string
+		err      string
+	}{
+		{options: map[string]string{}, expected: "unset"},
+		{options: map[string]string{"empty": "accept"}, expected: "accept"},
+		{options: map[string]string{"notEmpty": ""}, expected: "reject"},
+		{options: map[string]string{"notEmpty": "", "empty": "reject"}, expected: "reject"},
+		{options: map[string]string{"notEmpty": "", "empty": "accept"}, err: "options notEmpty and empty=accept conflict"},
+		{options: map[string]string{"empty": "ignore"}, err: `invalid empty option "ignore": must be unset, accept or reject`},
+	}
+
+	for _, tc := range testCases {
+		emptyValues, err := keyTag{options: tc.options}.emptyValues()
+		if tc.err != "" {
+			assert.EqualError(t, err, tc.err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, emptyValues)
+	}
+}
+
+func Test_KeyTagOptions(t *testing.T) {
+	keys := []string{"TAG_NO_OMITEMPTY_CACHE", "TAG_REQUIRED", "TAG_NOT_EMPTY", "TAG_SECRET", "TAG_SECRET_OLD", "TAG_SECRET_FILE", "TAG_FLAGS_A"}
+	reset := func() {
+		for _, k := range keys {
+			_ = os.Unsetenv(k)
+		}
+	}
+	reset()
+	defer reset()
+
+	t.Run("key containing an option name", func(t *testing.T) {
+		type TestConfig struct {
+			Cache string `env:"TAG_NO_OMITEMPTY_CACHE"`
+		}
+
+		err := NewDefault().Silent().Unmarshal(new(TestConfig))
+
+		var missingErr *MissingValueError
+		assert.True(t, errors.As(err, &missingErr))
+	})
+
+	t.Run("required", func(t *testing.T) {
+		type TestConfig struct {
+			Value string `env:"TAG_REQUIRED,required"`
+		}
+
+		err := NewDefault().Silent().Unmarshal(new(TestConfig))
+
+		var missingErr *MissingValueError
+		assert.True(t, errors.As(err, &missingErr))
+	})
+
+	t.Run("notEmpty", func(t *testing.T) {
+		type TestConfig struct {
+			Value string `env:"TAG_NOT_EMPTY,notEmpty" default:"fallback"`
+		}
+
+		_ = os.Setenv("TAG_NOT_EMPTY", "")
+		defer reset()
+
+		err := NewDefault().Silent().Unmarshal(new(TestConfig))
+
+		var emptyErr *EmptyValueError
+		assert.True(t, errors.As(err, &emptyErr))
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		type TestConfig struct {
+			Secret string          `env:"TAG_SECRET|TAG_SECRET_OLD,secret,unset"`
+			Flags  map[string]bool `env:"TAG_FLAGS_,prefix,unset"`
+		}
+
+		_ = os.Setenv("TAG_SECRET_OLD", "hunter2")
+		_ = os.Setenv("TAG_SECRET_FILE", "/run/secrets/unused")
+		_ = os.Setenv("TAG_FLAGS_A", "true")
+		defer reset()
+
+		cfg := new(TestConfig)
+		err := NewDefault().Silent().Unmarshal(cfg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", cfg.Secret)
+		assert.Equal(t, map[string]bool{"A": true}, cfg.Flags)
+
+		for _, k := range []string{"TAG_SECRET_OLD", "TAG_SECRET_FILE", "TAG_FLAGS_A"} {
+			_, ok := os.LookupEnv(k)
+			assert.False(t, ok, k)
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		type Group struct {
+			Value string `env:"TAG_GROUP_VALUE,omitempty"`
+		}
+
+		type TestConfig struct {
+			Unknown  string  `env:"TAG_UNKNOWN,omitempty,bogus"`
+			Conflict string  `env:"TAG_CONFLICT,omitempty,required"`
+			Count    []Group `env:"TAG_COUNT,min=one"`
+			Group    *Group  `env:",optional"`
+		}
+
+		err := NewDefault().Silent().CollectErrors().Unmarshal(new(TestConfig))
+
+		var multiErr *MultiError
+		assert.True(t, errors.As(err, &multiErr))
+		assert.Len(t, multiErr.Errors, 4)
+
+		var tagErr *InvalidTagError
+		assert.True(t, errors.As(multiErr.Errors[0], &tagErr))
+		assert.Equal(t, "TAG_UNKNOWN", tagErr.Key)
+
+		assert.Contains(t, err.Error(), `invalid tags on Unknown: unknown option "bogus"`)
+		assert.Contains(t, err.Error(), "invalid tags on Conflict: options omitempty and required conflict")
+		assert.Contains(t, err.Error(), `invalid tags on Count: invalid min option "one": must be an integer`)
+		assert.Contains(t, err.Error(), `invalid tags on Group: unknown option "optional"`)
+	})
+}
+
+func Test_UseTagNames(t *testing.T) {
+	type TestConfig struct {
+		Host  string `cfg:"TAGNAMES_HOST" cfgDefault:"localhost" default:"ignored" description:"swag description"`
+		Token string `cfg:"TAGNAMES_TOKEN,hidden,optional"`
+	}
+
+	_ = os.Unsetenv("TAGNAMES_HOST")
+	_ = os.Setenv("TAGNAMES_TOKEN", "hunter2")
+	defer func() { _ = os.Unsetenv("TAGNAMES_TOKEN") }()
+
+	names := DefaultTagNames()
+	names.Key = "cfg"
+	names.Default = "cfgDefault"
+	names.Description = ""
+	names.Secret = "hidden"
+	names.OmitEmpty = "optional"
+
+	cfgManager := NewDefault().Silent().UseTagNames(names)
+
+	cfg := new(TestConfig)
+	report, err := cfgManager.UnmarshalWithReport(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, &TestConfig{Host: "localhost", Token: "hunter2"}, cfg)
+
+	fields := cfgManager.DumpFields(cfg, report)
+	assert.Equal(t, "****", fields[1].Value)
+
+	doc := NewDoc()
+	cfgManager.parseDocGroup(doc, cfg)
+	assert.Equal(t, "", doc.Fields[0].Description)
+	assert.Equal(t, "localhost", doc.Fields[0].DefaultValue)
+	assert.True(t, doc.Fields[1].OmitEmpty)
+}
+
+func Test_EnvconfigTagNames(t *testing.T) {
+	type RedisConfig struct {
+		MaxConns int    `split_words:"true" default:"10"`
+		Host     string `required:"true"`
+	}
+
+	type TestConfig struct {
+		Port       int    `envconfig:"ENVCONFIG_PORT" default:"8080" desc:"Port to listen on"`
+		ApiKey     string `envconfig:"ENVCONFIG_API_KEY" required:"true"`
+		Debug      bool
+		Redis      RedisConfig
+		Unused     string `envconfig:"ENVCONFIG_UNUSED"`
+		Timeout    string `envconfig:"ENVCONFIG_TIMEOUT" required:"false"`
+		unexported string
+		Hosts      []string `envconfig:"ENVCONFIG_HOSTS"`
+	}
+
+	keys := []string{"ENVCONFIG_PORT", "ENVCONFIG_API_KEY", "DEBUG", "REDIS_MAX_CONNS", "REDIS_HOST", "ENVCONFIG_HOSTS"}
+	reset := func() {
+		for _, k := range keys {
+			_ = os.Unsetenv(k)
+		}
+	}
+	reset()
+	defer reset()
+
+	_ = os.Setenv("ENVCONFIG_API_KEY", "key")
+	_ = os.Setenv("DEBUG", "true")
+	_ = os.Setenv("REDIS_MAX_CONNS", "20")
+	_ = os.Setenv("REDIS_HOST", "redis")
+	_ = os.Setenv("ENVCONFIG_HOSTS", "a,b")
+
+	cfgManager := NewDefault().Silent().UseTagNames(EnvconfigTagNames())
+
+	cfg := new(TestConfig)
+	assert.NoError(t, cfgManager.Unmarshal(cfg))
+	assert.Equal(t, &TestConfig{
+		Port:   8080,
+		ApiKey: "key",
+		Debug:  true,
+		Redis:  RedisConfig{MaxConns: 20, Host: "redis"},
+		Hosts:  []string{"a", "b"},
+	}, cfg)
+
+	doc := NewDoc()
+	cfgManager.parseDocGroup(doc, cfg)
+	assert.Equal(t, "Port to listen on", doc.Fields[0].Description)
+	assert.False(t, doc.Fields[1].OmitEmpty)
+	assert.True(t, doc.Fields[2].OmitEmpty)
+	assert.Equal(t, "DEBUG", doc.Fields[2].Key)
+
+	_ = os.Unsetenv("REDIS_HOST")
+
+	err := cfgManager.Unmarshal(new(TestConfig))
+
+	var missingErr *MissingValueError
+	assert.True(t, errors.As(err, &missingErr))
+	assert.Equal(t, "REDIS_HOST", missingErr.Key)
+
+	type InvalidConfig struct {
+		Value string `envconfig:"ENVCONFIG_VALUE" required:"yes please"`
+	}
+
+	err = cfgManager.Unmarshal(new(InvalidConfig))
+	assert.EqualError(t, err, `invalid tags on Value: invalid required tag "yes please": must be a boolean`)
+}
+
+type envconfigServerConfig struct {
+	Port int    `envconfig:"PORT"`
+	Host string `split_words:"true"`
+}
+
+type EnvconfigEmbedded struct {
+	Region string
+}
+
+func Test_EnvconfigTagNames_NestedKeys(t *testing.T) {
+	type TestConfig struct {
+		EnvconfigEmbedded
+		Server  envconfigServerConfig
+		Backup  envconfigServerConfig `envconfig:"BACKUP_SERVER"`
+		Replica *envconfigServerConfig
+	}
+
+	keys := []string{"REGION", "SERVER_PORT", "SERVER_HOST", "BACKUP_SERVER_PORT", "BACKUP_SERVER_HOST", "PORT", "REPLICA_HOST"}
+	reset := func() {
+		for _, k := range keys {
+			_ = os.Unsetenv(k)
+		}
+	}
+	reset()
+	defer reset()
+
+	_ = os.Setenv("REGION", "eu")
+	_ = os.Setenv("SERVER_PORT", "8080")
+	_ = os.Setenv("SERVER_HOST", "server")
+	_ = os.Setenv("BACKUP_SERVER_PORT", "8081")
+	_ = os.Setenv("BACKUP_SERVER_HOST", "backup")
+	_ = os.Setenv("PORT", "9090")
+	_ = os.Setenv("REPLICA_HOST", "replica")
+
+	cfgManager := NewDefault().Silent().UseTagNames(EnvconfigTagNames())
+
+	cfg := new(TestConfig)
+	assert.NoError(t, cfgManager.Unmarshal(cfg))
+	assert.Equal(t, &TestConfig{
+		EnvconfigEmbedded: EnvconfigEmbedded{Region: "eu"},
+		Server:            envconfigServerConfig{Port: 8080, Host: "server"},
+		Backup:            envconfigServerConfig{Port: 8081, Host: "backup"},
+		Replica:           &envconfigServerConfig{Port: 9090, Host: "replica"},
+	}, cfg)
+
+	doc := NewDoc()
+	cfgManager.parseDocGroup(doc, cfg)
+	assert.Equal(t, "REGION", doc.Groups[0].Fields[0].Key)
+	assert.Equal(t, "SERVER_PORT", doc.Groups[1].Fields[0].Key)
+}
+
+func Test_EnvconfigTagNames_UpperCaseAndIgnored(t *testing.T) {
+	type TestConfig struct {
+		ManualOverride1 string `envconfig:"manual_override_1"`
+		IgnoredVar      string `ignored:"true"`
+		Server          struct {
+			Port int `envconfig:"port"`
+		}
+	}
+
+	keys := []string{"MANUAL_OVERRIDE_1", "IGNOREDVAR", "SERVER_PORT"}
+	reset := func() {
+		for _, k := range keys {
+			_ = os.Unsetenv(k)
+		}
+	}
+	reset()
+	defer reset()
+
+	_ = os.Setenv("MANUAL_OVERRIDE_1", "override")
+	_ = os.Setenv("IGNOREDVAR", "ignored")
+	_ = os.Setenv("SERVER_PORT", "8080")
+
+	cfgManager := NewDefault().Silent().UseTagNames(EnvconfigTagNames())
+
+	cfg := new(TestConfig)
+	assert.NoError(t, cfgManager.Unmarshal(cfg))
+	assert.Equal(t, "override", cfg.ManualOverride1)
+	assert.Empty(t, cfg.IgnoredVar)
+	assert.Equal(t, 8080, cfg.Server.Port)
+
+	doc := NewDoc()
+	cfgManager.parseDocGroup(doc, cfg)
+	assert.Len(t, doc.Fields, 1)
+	assert.Equal(t, "MANUAL_OVERRIDE_1", doc.Fields[0].Key)
+}
+
+func Test_CaarlosEnvTagNames(t *testing.T) {
+	type TestConfig struct {
+		Hosts  []string          `env:"CAARLOS_HOSTS" envSeparator:";"`
+		Labels map[string]string `env:"CAARLOS_LABELS" envSeparator:";" envKeyValSeparator:"="`
+		Port   int               `env:"CAARLOS_PORT" envDefault:"8080"`
+		Debug  bool              `env:"CAARLOS_DEBUG"`
+		Token  string            `env:"CAARLOS_TOKEN,required,notEmpty"`
+	}
+
+	keys := []string{"CAARLOS_HOSTS", "CAARLOS_LABELS", "CAARLOS_PORT", "CAARLOS_DEBUG", "CAARLOS_TOKEN"}
+	reset := func() {
+		for _, k := range keys {
+			_ = os.Unsetenv(k)
+		}
+	}
+	reset()
+	defer reset()
+
+	_ = os.Setenv("CAARLOS_HOSTS", "a;b")
+	_ = os.Setenv("CAARLOS_LABELS", "env=prod;team=core")
+	_ = os.Setenv("CAARLOS_TOKEN", "token")
+
+	cfgManager := NewDefault().Silent().UseTagNames(CaarlosEnvTagNames())
+
+	cfg := new(TestConfig)
+	assert.NoError(t, cfgManager.Unmarshal(cfg))
+	assert.Equal(t, &TestConfig{
+		Hosts:  []string{"a", "b"},
+		Labels: map[string]string{"env": "prod", "team": "core"},
+		Port:   8080,
+		Token:  "token",
+	}, cfg)
+
+	_ = os.Setenv("CAARLOS_TOKEN", "")
+
+	err := cfgManager.Unmarshal(new(TestConfig))
+
+	var emptyErr *EmptyValueError
+	assert.True(t, errors.As(err, &emptyErr))
+}
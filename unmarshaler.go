@@ -0,0 +1,61 @@
+package gocfg
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"reflect"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// implementsUnmarshaler reports whether a pointer to t implements one of the supported unmarshaler interfaces
+func implementsUnmarshaler(t reflect.Type) bool {
+	_, ok := getUnmarshalerParser(t)
+	return ok
+}
+
+// getUnmarshalerParser returns a parser for types whose pointer implements, in order of precedence,
+// encoding.TextUnmarshaler, flag.Value or json.Unmarshaler. json.Unmarshaler implementations receive the raw value
+// when it is valid JSON, e.g. 42 or {"a":1}, and the value as a JSON string otherwise, e.g. "info".
+func getUnmarshalerParser(t reflect.Type) (func(v string) (interface{}, error), bool) {
+	ptrType := reflect.PtrTo(t)
+
+	switch {
+	case ptrType.Implements(textUnmarshalerType):
+		return func(v string) (interface{}, error) {
+			ptr := reflect.New(t)
+			if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(v)); err != nil {
+				return nil, err
+			}
+			return ptr.Elem().Interface(), nil
+		}, true
+	case ptrType.Implements(flagValueType):
+		return func(v string) (interface{}, error) {
+			ptr := reflect.New(t)
+			if err := ptr.Interface().(flag.Value).Set(v); err != nil {
+				return nil, err
+			}
+			return ptr.Elem().Interface(), nil
+		}, true
+	case ptrType.Implements(jsonUnmarshalerType):
+		return func(v string) (interface{}, error) {
+			data := []byte(v)
+			if !json.Valid(data) {
+				data, _ = json.Marshal(v)
+			}
+
+			ptr := reflect.New(t)
+			if err := ptr.Interface().(json.Unmarshaler).UnmarshalJSON(data); err != nil {
+				return nil, err
+			}
+			return ptr.Elem().Interface(), nil
+		}, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,177 @@
+package gocfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLogLevel int
+
+func (l *testLogLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return fmt.Errorf("unknown log level")
+	}
+	return nil
+}
+
+// testHostPort implements both flag.Value and json.Unmarshaler, flag.Value takes precedence.
+type testHostPort struct {
+	Host string
+	Port string
+	From string
+}
+
+func (h *testHostPort) String() string {
+	return h.Host + ":" + h.Port
+}
+
+func (h *testHostPort) Set(v string) error {
+	host, port, err := net.SplitHostPort(v)
+	if err != nil {
+		return err
+	}
+	*h = testHostPort{Host: host, Port: port, From: "flag"}
+	return nil
+}
+
+func (h *testHostPort) UnmarshalJSON(data []byte) error {
+	h.From = "json"
+	return nil
+}
+
+type testEnumID string
+
+func (e *testEnumID) UnmarshalJSON(data []byte) error {
+	*e = testEnumID("id-" + string(data))
+	return nil
+}
+
+type testJSONLevel string
+
+func (l *testJSONLevel) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*l = testJSONLevel(strings.ToUpper(s))
+	return nil
+}
+
+func Test_UnmarshalerFields_JSONString(t *testing.T) {
+	type TestConfig struct {
+		Level  testJSONLevel `env:"UNMARSHALER_JSON_LEVEL"`
+		Quoted testJSONLevel `env:"UNMARSHALER_JSON_QUOTED"`
+	}
+
+	_ = os.Setenv("UNMARSHALER_JSON_LEVEL", "info")
+	_ = os.Setenv("UNMARSHALER_JSON_QUOTED", `"debug"`)
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, testJSONLevel("INFO"), cfg.Level)
+	assert.Equal(t, testJSONLevel("DEBUG"), cfg.Quoted)
+}
+
+func Test_UnmarshalerFields(t *testing.T) {
+	type TestConfig struct {
+		LogLevel    testLogLevel   `env:"UNMARSHALER_LOG_LEVEL"`
+		LogLevelPtr *testLogLevel  `env:"UNMARSHALER_LOG_LEVEL_PTR"`
+		Address     testHostPort   `env:"UNMARSHALER_ADDRESS"`
+		EnumID      testEnumID     `env:"UNMARSHALER_ENUM_ID"`
+		Levels      []testLogLevel `env:"UNMARSHALER_LEVELS"`
+		StartedAt   time.Time      `env:"UNMARSHALER_STARTED_AT"`
+		IP          net.IP         `env:"UNMARSHALER_IP"`
+	}
+
+	_ = os.Setenv("UNMARSHALER_LOG_LEVEL", "INFO")
+	_ = os.Setenv("UNMARSHALER_LOG_LEVEL_PTR", "debug")
+	_ = os.Setenv("UNMARSHALER_ADDRESS", "localhost:8080")
+	_ = os.Setenv("UNMARSHALER_ENUM_ID", "42")
+	_ = os.Setenv("UNMARSHALER_LEVELS", "debug,info")
+	_ = os.Setenv("UNMARSHALER_STARTED_AT", "2024-01-02T03:04:05Z")
+	_ = os.Setenv("UNMARSHALER_IP", "10.0.0.5")
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, testLogLevel(1), cfg.LogLevel)
+	assert.NotNil(t, cfg.LogLevelPtr)
+	assert.Equal(t, testLogLevel(0), *cfg.LogLevelPtr)
+	assert.Equal(t, testHostPort{Host: "localhost", Port: "8080", From: "flag"}, cfg.Address)
+	assert.Equal(t, testEnumID("id-42"), cfg.EnumID)
+	assert.Equal(t, []testLogLevel{0, 1}, cfg.Levels)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), cfg.StartedAt)
+	assert.Equal(t, net.ParseIP("10.0.0.5"), cfg.IP)
+}
+
+type dateParserProvider struct{}
+
+func (p *dateParserProvider) Get(field reflect.Value) (func(v string) (interface{}, error), bool) {
+	if field.Type() != reflect.TypeOf(time.Time{}) {
+		return nil, false
+	}
+	return func(v string) (interface{}, error) {
+		return time.Parse("2006-01-02", v)
+	}, true
+}
+
+func Test_UnmarshalerFields_ParserProviderPrecedence(t *testing.T) {
+	type TestConfig struct {
+		Date     time.Time    `env:"UNMARSHALER_DATE"`
+		LogLevel testLogLevel `env:"UNMARSHALER_PRECEDENCE_LOG_LEVEL"`
+	}
+
+	_ = os.Setenv("UNMARSHALER_DATE", "2024-01-02")
+	_ = os.Setenv("UNMARSHALER_PRECEDENCE_LOG_LEVEL", "info")
+
+	cfg := new(TestConfig)
+	err := NewDefault().AddParserProviders(&dateParserProvider{}).Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), cfg.Date)
+	assert.Equal(t, testLogLevel(1), cfg.LogLevel)
+}
+
+func Test_UnmarshalerFields_Invalid(t *testing.T) {
+	type TestConfig struct {
+		LogLevel testLogLevel `env:"UNMARSHALER_INVALID_LOG_LEVEL"`
+	}
+
+	_ = os.Setenv("UNMARSHALER_INVALID_LOG_LEVEL", "verbose")
+
+	err := NewDefault().Unmarshal(new(TestConfig))
+
+	var parseErr *ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Contains(t, err.Error(), "failed to parse UNMARSHALER_INVALID_LOG_LEVEL: unknown log level")
+}
+
+func Test_parseDocGroup_UnmarshalerStruct(t *testing.T) {
+	type TestConfig struct {
+		StartedAt time.Time `env:"STARTED_AT"`
+	}
+
+	docGroup := NewDoc()
+	NewEmpty().parseDocGroup(docGroup, new(TestConfig))
+
+	assert.Len(t, docGroup.Groups, 0)
+	assert.Len(t, docGroup.Fields, 1)
+	assert.Equal(t, "STARTED_AT", docGroup.Fields[0].Key)
+}
@@ -0,0 +1,319 @@
+package gocfg
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Validation rules supported by the validate tag, e.g. `validate:"min=1,max=65535"`
+const (
+	validateRuleMin      = "min"
+	validateRuleMax      = "max"
+	validateRuleLen      = "len"
+	validateRuleOneOf    = "oneof"
+	validateRuleRegexp   = "regexp"
+	validateRuleURL      = "url"
+	validateRuleHostPort = "hostport"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// validationRule is a single parsed rule of the validate tag
+type validationRule struct {
+	name  string
+	param string
+	check func(v reflect.Value) error
+}
+
+// String returns the rule as written in the tag
+func (r validationRule) String() string {
+	if r.param == "" {
+		return r.name
+	}
+	return r.name + "=" + r.param
+}
+
+// splitValidationRules splits the validate tag into name and param pairs.
+// Rules are separated by commas; the regexp rule consumes the rest of the tag, so it may contain commas itself.
+func splitValidationRules(tag string) [][2]string {
+	var rules [][2]string
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, validateRuleRegexp+"=") {
+			rule, tag = tag, ""
+		} else if i := strings.Index(tag, ","); i >= 0 {
+			rule, tag = tag[:i], tag[i+1:]
+		} else {
+			rule, tag = tag, ""
+		}
+
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		nameParam := strings.SplitN(rule, "=", 2)
+		if len(nameParam) == 1 {
+			nameParam = append(nameParam, "")
+		}
+		rules = append(rules, [2]string{nameParam[0], nameParam[1]})
+	}
+	return rules
+}
+
+// parseValidationRules parses the validate tag for a field of type t
+func parseValidationRules(tag string, t reflect.Type) ([]validationRule, error) {
+	var rules []validationRule
+	for _, nameParam := range splitValidationRules(tag) {
+		rule := validationRule{name: nameParam[0], param: nameParam[1]}
+
+		check, err := newValidationCheck(rule.name, rule.param, t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validation rule %s: %w", rule, err)
+		}
+
+		rule.check = check
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func newValidationCheck(name, param string, t reflect.Type) (func(v reflect.Value) error, error) {
+	switch name {
+	case validateRuleMin:
+		return newBoundCheck(param, t, func(cmp int) bool { return cmp >= 0 }, "at least")
+	case validateRuleMax:
+		return newBoundCheck(param, t, func(cmp int) bool { return cmp <= 0 }, "at most")
+	case validateRuleLen:
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return nil, err
+		}
+		if !hasLength(t) {
+			return nil, fmt.Errorf("unsupported type %s", t)
+		}
+		return func(v reflect.Value) error {
+			if length(v) != n {
+				return fmt.Errorf("length must be %d", n)
+			}
+			return nil
+		}, nil
+	case validateRuleOneOf:
+		allowed := strings.Fields(param)
+		if len(allowed) == 0 {
+			return nil, fmt.Errorf("no values")
+		}
+		return func(v reflect.Value) error {
+			s := fmt.Sprint(v.Interface())
+			for _, a := range allowed {
+				if s == a {
+					return nil
+				}
+			}
+			return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+		}, nil
+	case validateRuleRegexp:
+		re, err := regexp.Compile(param)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) error {
+			if !re.MatchString(fmt.Sprint(v.Interface())) {
+				return fmt.Errorf("must match %s", re)
+			}
+			return nil
+		}, nil
+	case validateRuleURL:
+		if t.Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported type %s", t)
+		}
+		schemes := strings.Fields(param)
+		return func(v reflect.Value) error {
+			return checkURL(v.String(), schemes)
+		}, nil
+	case validateRuleHostPort:
+		if t.Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported type %s", t)
+		}
+		return func(v reflect.Value) error {
+			return checkHostPort(v.String())
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown rule")
+	}
+}
+
+// newBoundCheck builds a min or max check. Numbers are compared by value, strings, slices, arrays and maps by length.
+func newBoundCheck(param string, t reflect.Type, ok func(cmp int) bool, relation string) (func(v reflect.Value) error, error) {
+	if hasLength(t) {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) error {
+			if !ok(compareInts(int64(length(v)), int64(n))) {
+				return fmt.Errorf("length must be %s %d", relation, n)
+			}
+			return nil
+		}, nil
+	}
+
+	switch {
+	case t == durationType:
+		d, err := time.ParseDuration(param)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) error {
+			if !ok(compareInts(v.Int(), int64(d))) {
+				return fmt.Errorf("must be %s %s", relation, d)
+			}
+			return nil
+		}, nil
+	case isIntKind(t.Kind()):
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) error {
+			if !ok(compareInts(v.Int(), n)) {
+				return fmt.Errorf("must be %s %d", relation, n)
+			}
+			return nil
+		}, nil
+	case isUintKind(t.Kind()):
+		n, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) error {
+			if !ok(compareUints(v.Uint(), n)) {
+				return fmt.Errorf("must be %s %d", relation, n)
+			}
+			return nil
+		}, nil
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) error {
+			if !ok(compareFloats(v.Float(), f)) {
+				return fmt.Errorf("must be %s %s", relation, param)
+			}
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+func checkURL(s string, schemes []string) error {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("must be an absolute URL")
+	}
+
+	if len(schemes) == 0 {
+		return nil
+	}
+
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("URL scheme must be one of %s", strings.Join(schemes, ", "))
+}
+
+func checkHostPort(s string) error {
+	_, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return fmt.Errorf("must be in host:port form")
+	}
+
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("port must be a number between 0 and 65535")
+	}
+
+	return nil
+}
+
+func hasLength(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+// length returns the number of characters of a string or the number of elements of a slice, array or map
+func length(v reflect.Value) int {
+	if v.Kind() == reflect.String {
+		return utf8.RuneCountInString(v.String())
+	}
+	return v.Len()
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// validate runs the validation rules of the field against its parsed value
+func (s fieldSpec) validate(v reflect.Value, value, provider string) error {
+	for _, rule := range s.rules {
+		if err := rule.check(v); err != nil {
+			return &ValidationError{
+				FieldError: s.fieldError(value, provider, err),
+				Rule:       rule.String(),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package gocfg
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Validation(t *testing.T) {
+	type TestConfig struct {
+		Port     uint16        `env:"VALIDATE_PORT" validate:"min=1,max=65535"`
+		Ratio    float64       `env:"VALIDATE_RATIO" validate:"min=0,max=1"`
+		Offset   int           `env:"VALIDATE_OFFSET" validate:"min=-10"`
+		Timeout  time.Duration `env:"VALIDATE_TIMEOUT" validate:"min=1s,max=1m"`
+		LogLevel string        `env:"VALIDATE_LOG_LEVEL" validate:"oneof=debug info warn"`
+		Name     string        `env:"VALIDATE_NAME" validate:"min=2,max=8,regexp=^[a-z]{1,8}$"`
+		Code     string        `env:"VALIDATE_CODE" validate:"len=3"`
+		Hosts    []string      `env:"VALIDATE_HOSTS" validate:"min=1"`
+		Endpoint string        `env:"VALIDATE_ENDPOINT" validate:"url=http https"`
+		Address  string        `env:"VALIDATE_ADDRESS" validate:"hostport"`
+		Optional *int          `env:"VALIDATE_OPTIONAL,omitempty" validate:"min=1"`
+	}
+
+	_ = os.Setenv("VALIDATE_PORT", "8080")
+	_ = os.Setenv("VALIDATE_RATIO", "0.5")
+	_ = os.Setenv("VALIDATE_OFFSET", "-5")
+	_ = os.Setenv("VALIDATE_TIMEOUT", "30s")
+	_ = os.Setenv("VALIDATE_LOG_LEVEL", "info")
+	_ = os.Setenv("VALIDATE_NAME", "app")
+	_ = os.Setenv("VALIDATE_CODE", "abc")
+	_ = os.Setenv("VALIDATE_HOSTS", "a,b")
+	_ = os.Setenv("VALIDATE_ENDPOINT", "https://example.com/api")
+	_ = os.Setenv("VALIDATE_ADDRESS", "localhost:6379")
+	_ = os.Unsetenv("VALIDATE_OPTIONAL")
+
+	cfg := new(TestConfig)
+	err := NewDefault().Unmarshal(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(8080), cfg.Port)
+	assert.Nil(t, cfg.Optional)
+}
+
+func Test_Validation_Failures(t *testing.T) {
+	type TestConfig struct {
+		Port     uint16         `env:"VALIDATE_FAIL_PORT" validate:"min=1"`
+		Ratio    float64        `env:"VALIDATE_FAIL_RATIO" validate:"max=1"`
+		Timeout  time.Duration  `env:"VALIDATE_FAIL_TIMEOUT" validate:"max=1m"`
+		LogLevel string         `env:"VALIDATE_FAIL_LOG_LEVEL" validate:"oneof=debug info warn"`
+		Name     string         `env:"VALIDATE_FAIL_NAME" validate:"regexp=^[a-z]{1,8}$"`
+		Code     string         `env:"VALIDATE_FAIL_CODE" validate:"len=3"`
+		Endpoint string         `env:"VALIDATE_FAIL_ENDPOINT" validate:"url=https"`
+		Address  string         `env:"VALIDATE_FAIL_ADDRESS" validate:"hostport"`
+		Weights  map[string]int `env:"VALIDATE_FAIL_WEIGHT_,prefix" validate:"max=1"`
+	}
+
+	_ = os.Setenv("VALIDATE_FAIL_PORT", "0")
+	_ = os.Setenv("VALIDATE_FAIL_RATIO", "1.5")
+	_ = os.Setenv("VALIDATE_FAIL_TIMEOUT", "2m")
+	_ = os.Setenv("VALIDATE_FAIL_LOG_LEVEL", "verbos")
+	_ = os.Setenv("VALIDATE_FAIL_NAME", "App")
+	_ = os.Setenv("VALIDATE_FAIL_CODE", "abcd")
+	_ = os.Setenv("VALIDATE_FAIL_ENDPOINT", "http://example.com")
+	_ = os.Setenv("VALIDATE_FAIL_ADDRESS", "localhost")
+	_ = os.Setenv("VALIDATE_FAIL_WEIGHT_A", "1")
+	_ = os.Setenv("VALIDATE_FAIL_WEIGHT_B", "2")
+
+	err := NewDefault().CollectErrors().Unmarshal(new(TestConfig))
+
+	var multiErr *MultiError
+	assert.True(t, errors.As(err, &multiErr))
+	assert.Len(t, multiErr.Errors, 9)
+
+	expected := []string{
+		"Port: VALIDATE_FAIL_PORT does not satisfy min=1: must be at least 1",
+		"Ratio: VALIDATE_FAIL_RATIO does not satisfy max=1: must be at most 1",
+		"Timeout: VALIDATE_FAIL_TIMEOUT does not satisfy max=1m: must be at most 1m0s",
+		"LogLevel: VALIDATE_FAIL_LOG_LEVEL does not satisfy oneof=debug info warn: must be one of debug, info, warn",
+		"Name: VALIDATE_FAIL_NAME does not satisfy regexp=^[a-z]{1,8}$: must match ^[a-z]{1,8}$",
+		"Code: VALIDATE_FAIL_CODE does not satisfy len=3: length must be 3",
+		"Endpoint: VALIDATE_FAIL_ENDPOINT does not satisfy url=https: URL scheme must be one of https",
+		"Address: VALIDATE_FAIL_ADDRESS does not satisfy hostport: must be in host:port form",
+		"Weights: VALIDATE_FAIL_WEIGHT_ does not satisfy max=1: length must be at most 1",
+	}
+	for i, msg := range expected {
+		assert.Equal(t, msg, multiErr.Errors[i].Error())
+	}
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(multiErr.Errors[0], &validationErr))
+	assert.Equal(t, "min=1", validationErr.Rule)
+	assert.Equal(t, "VALIDATE_FAIL_PORT", validationErr.Key)
+	assert.Equal(t, "0", validationErr.Value)
+}
+
+func Test_Validation_InvalidTag(t *testing.T) {
+	testCases := map[string]interface{}{
+		"unknown rule": &struct {
+			F string `env:"VALIDATE_TAG" validate:"unknown"`
+		}{},
+		"invalid bound": &struct {
+			F int `env:"VALIDATE_TAG" validate:"min=a"`
+		}{},
+		"unsupported bound": &struct {
+			F bool `env:"VALIDATE_TAG" validate:"max=1"`
+		}{},
+		"invalid regexp": &struct {
+			F string `env:"VALIDATE_TAG" validate:"regexp=("`
+		}{},
+		"empty oneof": &struct {
+			F string `env:"VALIDATE_TAG" validate:"oneof="`
+		}{},
+		"unsupported url": &struct {
+			F int `env:"VALIDATE_TAG" validate:"url"`
+		}{},
+		"unsupported len": &struct {
+			F int `env:"VALIDATE_TAG" validate:"len=1"`
+		}{},
+		"unsupported host": &struct {
+			F int `env:"VALIDATE_TAG" validate:"hostport"`
+		}{},
+		"invalid duration": &struct {
+			F time.Duration `env:"VALIDATE_TAG" validate:"min=1"`
+		}{},
+		"invalid uint bound": &struct {
+			F uint `env:"VALIDATE_TAG" validate:"min=-1"`
+		}{},
+	}
+
+	_ = os.Setenv("VALIDATE_TAG", "1")
+
+	for name, cfg := range testCases {
+		err := NewDefault().Unmarshal(cfg)
+
+		var tagErr *InvalidTagError
+		assert.True(t, errors.As(err, &tagErr), name)
+		assert.Contains(t, err.Error(), "invalid tags on F: invalid validation rule", name)
+	}
+}
+
+func Test_splitValidationRules(t *testing.T) {
+	assert.Equal(t, [][2]string{{"min", "1"}, {"max", "10"}}, splitValidationRules("min=1, max=10"))
+	assert.Equal(t, [][2]string{{"hostport", ""}}, splitValidationRules("hostport"))
+	assert.Equal(t, [][2]string{{"len", "2"}, {"regexp", "^[a-z]{1,8}$"}}, splitValidationRules("len=2,regexp=^[a-z]{1,8}$"))
+	assert.Nil(t, splitValidationRules(""))
+}
+
+func Test_parseValidationRules_HostPort(t *testing.T) {
+	rules, err := parseValidationRules("hostport", reflect.TypeOf(""))
+	assert.NoError(t, err)
+
+	assert.NoError(t, rules[0].check(reflect.ValueOf(":8080")))
+	assert.Error(t, rules[0].check(reflect.ValueOf("localhost:http")))
+}
+
+func Test_parseDocGroup_Validation(t *testing.T) {
+	type TestConfig struct {
+		LogLevel string   `env:"LOG_LEVEL" validate:"oneof=debug info"`
+		Port     uint16   `env:"PORT" validate:"min=1,max=65535"`
+		Name     string   `env:"NAME" validate:"min=3,max=10"`
+		Hosts    []string `env:"HOSTS" validate:"min=1"`
+	}
+
+	docGroup := NewDoc()
+	NewEmpty().parseDocGroup(docGroup, new(TestConfig))
+
+	assert.Equal(t, []string{"debug", "info"}, docGroup.Fields[0].AllowedValues)
+	assert.Equal(t, "1", docGroup.Fields[1].Min)
+	assert.Equal(t, "65535", docGroup.Fields[1].Max)
+	assert.Empty(t, docGroup.Fields[2].Min)
+	assert.Empty(t, docGroup.Fields[2].Max)
+	assert.Empty(t, docGroup.Fields[3].Min)
+}
@@ -0,0 +1,202 @@
+package gocfg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Reloader is an optional interface for value providers whose values can be re-read, such as env files.
+// Watch reloads such providers before every reload of the configuration.
+type Reloader interface {
+	Reload() error
+}
+
+// WatchTrigger calls reload whenever the configuration should be reloaded, until ctx is done.
+// It calls ready once it listens for the events it reacts to, e.g. once signals are caught:
+// Watch returns only when every trigger is ready or has returned.
+type WatchTrigger func(ctx context.Context, reload func(), ready func())
+
+// PollEvery triggers a reload at every interval
+func PollEvery(interval time.Duration) WatchTrigger {
+	return func(ctx context.Context, reload func(), ready func()) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		ready()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reload()
+			}
+		}
+	}
+}
+
+// OnSignal triggers a reload whenever the process receives one of the signals, e.g. syscall.SIGHUP.
+// The signals are caught before Watch returns and until its context is done.
+func OnSignal(signals ...os.Signal) WatchTrigger {
+	return func(ctx context.Context, reload func(), ready func()) {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, signals...)
+		defer signal.Stop(ch)
+		ready()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				reload()
+			}
+		}
+	}
+}
+
+// Watcher holds the current configuration and replaces it on every successful reload
+type Watcher struct {
+	ctx context.Context
+	c   *ConfigManager
+	typ reflect.Type
+
+	current atomic.Value
+
+	// mu serializes reloads and guards the handlers, which are called once it is released
+	mu          sync.Mutex
+	subscribers []func(old, new interface{})
+	errHandlers []func(err error)
+}
+
+// Watch loads cfg, a pointer to a struct, and keeps reloading the configuration into fresh structs of the same type
+// whenever one of the triggers fires, until ctx is done. Without triggers, reloads only happen through Reload.
+//
+// Every reload re-reads the value providers implementing Reloader, then runs UnmarshalContext with ctx, including validation
+// and hooks. A reloaded configuration different from the current one is published atomically and passed
+// to the subscribers; a failed reload keeps the last good configuration and is reported to the error handlers.
+//
+// The error of the initial load is returned as is, and no watching takes place then.
+func (c *ConfigManager) Watch(ctx context.Context, cfg interface{}, triggers ...WatchTrigger) (*Watcher, error) {
+	if err := c.UnmarshalContext(ctx, cfg); err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		ctx: ctx,
+		c:   c,
+		typ: reflect.TypeOf(cfg).Elem(),
+	}
+	w.current.Store(cfg)
+
+	reload := func() {
+		_ = w.Reload()
+	}
+
+	var wg sync.WaitGroup
+	for _, trigger := range triggers {
+		var once sync.Once
+		ready := func() {
+			once.Do(wg.Done)
+		}
+
+		wg.Add(1)
+		go func(trigger WatchTrigger) {
+			defer ready()
+			trigger(ctx, reload, ready)
+		}(trigger)
+	}
+	wg.Wait()
+
+	return w, nil
+}
+
+// Current returns the current configuration, a pointer to a struct of the type passed to Watch.
+// It must not be modified, as it is shared with all callers.
+func (w *Watcher) Current() interface{} {
+	return w.current.Load()
+}
+
+// Subscribe registers fn to be called with the previous and the new configuration after every change.
+// Subscribers are called one by one, from the goroutine that performed the reload, once the reload is complete,
+// so they may call Reload, Subscribe and OnError.
+func (w *Watcher) Subscribe(fn func(old, new interface{})) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// OnError registers fn to be called with the error of every failed reload, the same way as subscribers
+func (w *Watcher) OnError(fn func(err error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.errHandlers = append(w.errHandlers, fn)
+}
+
+// Reload reloads the configuration immediately, returning the error of a failed reload
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	notify, err := w.reload()
+	w.mu.Unlock()
+
+	notify()
+	return err
+}
+
+// reload reloads the configuration while w.mu is held. It returns a function calling the handlers concerned
+// with the outcome, copied under the lock, to be called once the lock is released.
+func (w *Watcher) reload() (notify func(), err error) {
+	cfg := reflect.New(w.typ).Interface()
+
+	err = w.reloadProviders()
+	if err == nil {
+		err = w.c.UnmarshalContext(w.ctx, cfg)
+	}
+
+	if err != nil {
+		w.c.logger.Error("failed to reload config, keeping the last good one", "error", err)
+
+		errHandlers := append(w.errHandlers[:0:0], w.errHandlers...)
+		return func() {
+			for _, fn := range errHandlers {
+				fn(err)
+			}
+		}, err
+	}
+
+	old := w.current.Load()
+	if reflect.DeepEqual(old, cfg) {
+		return func() {}, nil
+	}
+
+	w.current.Store(cfg)
+	w.c.logger.Info("config reloaded")
+
+	subscribers := append(w.subscribers[:0:0], w.subscribers...)
+	return func() {
+		for _, fn := range subscribers {
+			fn(old, cfg)
+		}
+	}, nil
+}
+
+func (w *Watcher) reloadProviders() error {
+	for _, p := range w.c.valueProviders {
+		reloader, ok := underlyingProvider(p).(Reloader)
+		if !ok {
+			continue
+		}
+
+		if err := reloader.Reload(); err != nil {
+			return fmt.Errorf("failed to reload %s: %w", providerName(p), err)
+		}
+	}
+	return nil
+}
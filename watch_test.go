@@ -0,0 +1,170 @@
+package gocfg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Jagerente/gocfg/pkg/parsers"
+	"github.com/Jagerente/gocfg/pkg/values"
+	"github.com/stretchr/testify/assert"
+)
+
+type watchConfig struct {
+	Feature bool `env:"WATCH_FEATURE"`
+	Workers int  `env:"WATCH_WORKERS" validate:"min=1"`
+}
+
+func newWatchEnvFile(t *testing.T, content string) (string, *values.DotEnvProvider) {
+	path := t.TempDir() + "/.env"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	provider, err := values.NewDotEnvProvider(path)
+	assert.NoError(t, err)
+
+	return path, provider
+}
+
+func Test_Watch_Reload(t *testing.T) {
+	path, provider := newWatchEnvFile(t, "WATCH_FEATURE=false\nWATCH_WORKERS=2")
+
+	initial := new(watchConfig)
+	w, err := NewEmpty().
+		Silent().
+		AddParserProviders(parsers.NewDefaultParserProvider()).
+		AddValueProviders(provider).
+		Watch(context.Background(), initial)
+	assert.NoError(t, err)
+	assert.Equal(t, &watchConfig{Feature: false, Workers: 2}, initial)
+	assert.Same(t, initial, w.Current())
+
+	var (
+		changes [][2]interface{}
+		errs    []error
+	)
+	w.Subscribe(func(old, new interface{}) {
+		changes = append(changes, [2]interface{}{old, new})
+	})
+	w.OnError(func(err error) {
+		errs = append(errs, err)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		assert.NoError(t, w.Reload())
+		assert.Empty(t, changes)
+		assert.Same(t, initial, w.Current())
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(path, []byte("WATCH_FEATURE=true\nWATCH_WORKERS=4"), 0o600))
+
+		assert.NoError(t, w.Reload())
+		assert.Equal(t, &watchConfig{Feature: true, Workers: 4}, w.Current())
+		assert.Equal(t, [][2]interface{}{{initial, w.Current()}}, changes)
+		assert.Equal(t, &watchConfig{Feature: false, Workers: 2}, initial)
+	})
+
+	t.Run("invalid keeps last good config", func(t *testing.T) {
+		good := w.Current()
+		assert.NoError(t, os.WriteFile(path, []byte("WATCH_FEATURE=true\nWATCH_WORKERS=0"), 0o600))
+
+		err := w.Reload()
+
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, []error{err}, errs)
+		assert.Same(t, good, w.Current())
+		assert.Len(t, changes, 1)
+	})
+
+	t.Run("unreadable provider keeps last good config", func(t *testing.T) {
+		good := w.Current()
+		assert.NoError(t, os.Remove(path))
+
+		err := w.Reload()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to reload dotenv")
+		assert.Same(t, good, w.Current())
+	})
+}
+
+func Test_Watch_InitialError(t *testing.T) {
+	_, provider := newWatchEnvFile(t, "WATCH_FEATURE=true")
+
+	w, err := NewDefault().Silent().AddValueProviders(provider).Watch(context.Background(), new(watchConfig))
+
+	assert.Nil(t, w)
+	assert.Error(t, err)
+}
+
+// testWatchTrigger checks that a change is reloaded once fire makes the trigger fire, right after Watch returns
+func testWatchTrigger(t *testing.T, trigger WatchTrigger, fire func()) {
+	path, provider := newWatchEnvFile(t, "WATCH_FEATURE=false\nWATCH_WORKERS=1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, err := NewDefault().Silent().AddValueProviders(provider).Watch(ctx, new(watchConfig), trigger)
+	assert.NoError(t, err)
+
+	changed := make(chan interface{}, 1)
+	w.Subscribe(func(_, new interface{}) {
+		changed <- new
+	})
+
+	assert.NoError(t, os.WriteFile(path, []byte("WATCH_FEATURE=true\nWATCH_WORKERS=1"), 0o600))
+	fire()
+
+	select {
+	case cfg := <-changed:
+		assert.Equal(t, &watchConfig{Feature: true, Workers: 1}, cfg)
+	case <-time.After(2 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+}
+
+func Test_Watch_PollEvery(t *testing.T) {
+	testWatchTrigger(t, PollEvery(10*time.Millisecond), func() {})
+}
+
+func Test_Watch_TriggerReturningEarly(t *testing.T) {
+	_, provider := newWatchEnvFile(t, "WATCH_FEATURE=false\nWATCH_WORKERS=1")
+
+	returned := func(ctx context.Context, reload func(), ready func()) {}
+
+	w, err := NewDefault().Silent().AddValueProviders(provider).Watch(context.Background(), new(watchConfig), returned)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, w)
+}
+
+func Test_Watch_ReentrantHandlers(t *testing.T) {
+	path, provider := newWatchEnvFile(t, "WATCH_FEATURE=false\nWATCH_WORKERS=1")
+
+	w, err := NewDefault().Silent().AddValueProviders(provider).Watch(context.Background(), new(watchConfig))
+	assert.NoError(t, err)
+
+	var changes, failures int
+	w.Subscribe(func(_, _ interface{}) {
+		w.Subscribe(func(_, _ interface{}) {})
+		changes++
+		assert.NoError(t, w.Reload())
+	})
+	w.OnError(func(err error) {
+		w.OnError(func(err error) {})
+		if failures++; failures == 1 {
+			assert.Error(t, w.Reload())
+		}
+	})
+
+	assert.NoError(t, os.WriteFile(path, []byte("WATCH_FEATURE=true\nWATCH_WORKERS=1"), 0o600))
+	assert.NoError(t, w.Reload())
+	assert.Equal(t, 1, changes)
+
+	assert.NoError(t, os.WriteFile(path, []byte("WATCH_FEATURE=true\nWATCH_WORKERS=0"), 0o600))
+	assert.Error(t, w.Reload())
+	assert.Equal(t, 2, failures)
+}
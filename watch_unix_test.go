@@ -0,0 +1,15 @@
+//go:build unix
+
+package gocfg
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func Test_Watch_OnSignal(t *testing.T) {
+	testWatchTrigger(t, OnSignal(syscall.SIGUSR1), func() {
+		_ = syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	})
+}